@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/johnayoung/go-agent-kit/internal/symbols"
+	"github.com/spf13/cobra"
+)
+
+// goSymbolsPath is where the index command writes the Go symbol index
+const goSymbolsPath = ".github/agent-kit/go-symbols.md"
+
+// indexCmd represents the index command
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Generate an index of the exported Go API",
+	Long: `Index lists the exported types, interfaces, functions and methods of every Go
+package in the repository, with the first sentence of their doc comments, so
+workflows can reuse existing interfaces instead of rediscovering them before
+every change.
+
+The source is read with go/parser: nothing is built or downloaded. Test
+files, main packages, testdata, vendor and gitignored files are left out.
+
+The index is written to .github/agent-kit/go-symbols.md; installed prompt
+files refer to it once it exists (run upgrade after the first index). Doc
+comments, then methods, then whole packages are left out until the index
+fits --budget.`,
+	RunE: runIndex,
+}
+
+var (
+	// indexDir is the --dir repository to index
+	indexDir = "."
+	// indexBudget is the --budget size limit in bytes
+	indexBudget = symbols.DefaultBudget
+	// indexStdout is set by --stdout to print the index instead of writing it
+	indexStdout bool
+)
+
+func runIndex(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	root := indexDir
+
+	ix, err := symbols.Build(root)
+	if err != nil {
+		return fmt.Errorf("failed to index Go packages: %w", err)
+	}
+	if len(ix.Packages) == 0 {
+		return fmt.Errorf("no Go packages found in %s", root)
+	}
+	content := ix.Markdown(indexBudget)
+
+	if indexStdout {
+		fmt.Fprint(out, content)
+		return nil
+	}
+
+	target := filepath.Join(root, filepath.FromSlash(goSymbolsPath))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(goSymbolsPath), err)
+	}
+	if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", goSymbolsPath, err)
+	}
+
+	fmt.Fprintf(out, "✅ Wrote %s (%d packages, %d bytes)\n", goSymbolsPath, len(ix.Packages), len(content))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(indexCmd)
+
+	indexCmd.Flags().StringVar(&indexDir, "dir", indexDir, "repository directory to index")
+	indexCmd.Flags().IntVar(&indexBudget, "budget", indexBudget, "maximum size of the index in bytes")
+	indexCmd.Flags().BoolVar(&indexStdout, "stdout", false, "print the index instead of writing it")
+}
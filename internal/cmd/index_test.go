@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	defer enterTempDir(t)()
+
+	// Nothing to index yet
+	if _, err := runCommand(t, runIndex, nil, ""); err == nil {
+		t.Error("Expected an error without Go packages")
+	}
+
+	files := map[string]string{
+		"go.mod":              "module example.com/svc\n",
+		"internal/api/api.go": "// Package api serves the HTTP API.\npackage api\n\n// Handler serves one route\ntype Handler interface {\n\tServe() error\n}\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output, err := runCommand(t, runIndex, nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, goSymbolsPath) {
+		t.Errorf("Expected the index path in the output:\n%s", output)
+	}
+	content, err := os.ReadFile(goSymbolsPath)
+	if err != nil {
+		t.Fatalf("Expected the index to be written: %v", err)
+	}
+	if !strings.Contains(string(content), "- `type Handler interface`: Handler serves one route\n  - `Serve() error`") {
+		t.Errorf("Expected the interface in the index:\n%s", content)
+	}
+
+	// Installed prompts point at the index
+	if _, err := runCommand(t, runInstall, nil, ""); err != nil {
+		t.Fatalf("Unexpected install error: %v", err)
+	}
+	prompt, err := os.ReadFile(".github/prompts/refactor.prompt.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(prompt), "Check the Go symbol index in `.github/agent-kit/go-symbols.md`") {
+		t.Errorf("Expected the prompt to refer to the index:\n%s", prompt)
+	}
+}
@@ -1,11 +1,22 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/johnayoung/go-agent-kit/internal/installer"
+	"github.com/johnayoung/go-agent-kit/internal/project"
 	"github.com/johnayoung/go-agent-kit/internal/templates"
+	"github.com/johnayoung/go-agent-kit/internal/version"
 	"github.com/spf13/cobra"
 )
 
@@ -17,180 +28,518 @@ var installCmd = &cobra.Command{
 how to use the go-agent-kit workflows in your project.
 
 After running this command, you can use GitHub Copilot commands like:
-  /feat add user authentication
-  /fix null pointer exception
+%s
 
 The install command creates language-agnostic instructions that work with any
-programming language or framework.`,
+programming language or framework.
+
+Use --dry-run to preview which files would be created or overwritten, with a
+unified diff against what is currently on disk.
+
+The generated instructions live between <!-- go-agent-kit:begin v1 --> and
+<!-- go-agent-kit:end --> markers in copilot-instructions.md. Reinstalling
+updates only that region; your own instructions above and below it are kept.
+
+Every generated file is recorded with its template, version and SHA-256 hash
+in .github/.go-agent-kit.lock. Prompt files that still match the recorded hash
+are updated freely; files you have edited since are left alone by default.
+Use --on-conflict to choose what happens to them:
+  skip       keep the existing file (default)
+  overwrite  replace the existing file
+  backup     save a timestamped .bak copy, then replace the file
+  prompt     ask file by file
+  merge      keep copilot-instructions.md as it is and add the managed block
+             (prompt files are generated whole and cannot be merged)
+
+All workflows (%s) are installed unless
+--only or --exclude select a subset, e.g. --only fix,refactor. The generated
+copilot-instructions.md documents only the commands actually installed.
+
+In a monorepo, every sub-project (nested go.mod, package.json or pnpm
+workspace member, Cargo workspace member) also gets a path-scoped
+.github/instructions/<dir>.instructions.md with its language, frameworks and
+verification commands, applied by Copilot only to files beneath it. The
+files of sub-projects since removed or renamed are deleted unless edited.
+
+Templates in .go-agent-kit/templates/ (project) and
+$XDG_CONFIG_HOME/go-agent-kit/templates/ (user) override the built-in
+workflows of the same name, or add new ones; the project wins over the user.
+
+By default install writes into the current directory. Use --dir to target
+another repository, or --recursive to find every git repository beneath the
+directory and install into each, with a per-repository summary.`,
 	RunE: runInstall,
 }
 
+var (
+	// installDryRun is set by --dry-run to preview changes without writing them
+	installDryRun bool
+	// installConflict is the --on-conflict policy for files that already exist
+	installConflict = string(installer.PolicySkip)
+	// installDir is the --dir repository to install into
+	installDir = "."
+	// installRecursive is set by --recursive to install into every repository beneath installDir
+	installRecursive bool
+	// installOnly and installExclude select which workflows to install
+	installOnly    []string
+	installExclude []string
+)
+
+// now returns the current time; tests replace it for stable backup names
+var now = time.Now
+
 func runInstall(cmd *cobra.Command, args []string) error {
-	// Create .github directory if it doesn't exist
-	githubDir := filepath.Join(".", ".github")
-	if err := os.MkdirAll(githubDir, 0755); err != nil {
-		return fmt.Errorf("failed to create .github directory: %w", err)
-	}
+	out := cmd.OutOrStdout()
 
-	// Create .github/prompts directory if it doesn't exist
-	promptsDir := filepath.Join(githubDir, "prompts")
-	if err := os.MkdirAll(promptsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create .github/prompts directory: %w", err)
+	policy, err := installer.ParsePolicy(installConflict)
+	if err != nil {
+		return err
 	}
 
-	// Install prompt files
-	if err := installPromptFiles(promptsDir); err != nil {
-		return fmt.Errorf("failed to install prompt files: %w", err)
+	// One reader for the whole run, so answers piped for later repositories
+	// are not buffered away by the first one
+	ask := askConflict(cmd)
+	if installRecursive {
+		return installRecursively(cmd, installDir, policy, ask)
 	}
 
-	// Generate the copilot instructions
-	instructions := generateCopilotInstructions()
-
-	// Write the instructions file
-	instructionsPath := filepath.Join(githubDir, "copilot-instructions.md")
-	if err := os.WriteFile(instructionsPath, []byte(instructions), 0644); err != nil {
-		return fmt.Errorf("failed to write copilot instructions: %w", err)
+	ops, selected, err := installInto(cmd, installDir, policy, ask)
+	if err != nil || installDryRun {
+		return err
 	}
 
-	fmt.Println("✅ GitHub Copilot integration installed successfully!")
-	fmt.Println()
-	fmt.Println("Available commands in GitHub Copilot Chat:")
-	fmt.Println("  /refactor [description] - Systematic code refactoring workflow")
-	fmt.Println("  /instructions           - Generate GitHub Copilot instructions")
-	fmt.Println()
-	fmt.Println("Example usage:")
-	fmt.Println("  /refactor simplify user authentication logic")
-	fmt.Println("  /instructions")
+	fmt.Fprintln(out, "✅ Successfully installed GitHub Copilot integration!")
+	fmt.Fprintln(out)
+	for _, op := range ops {
+		fmt.Fprintf(out, "  %s %s", resultLabel(op.Action), op.Path)
+		switch {
+		case op.Action == installer.ActionSkip && op.Orphaned:
+			fmt.Fprint(out, " (no longer generated, but modified locally; left in place)")
+		case op.Action == installer.ActionSkip:
+			fmt.Fprint(out, " (modified locally; see --on-conflict)")
+		case op.Action == installer.ActionBackup:
+			fmt.Fprintf(out, " (backup: %s)", op.BackupPath)
+		}
+		fmt.Fprintln(out)
+	}
+	fmt.Fprintf(out, "  Recorded: %s\n", installer.ManifestPath)
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Available commands in GitHub Copilot Chat:")
+	w := tabwriter.NewWriter(out, 0, 0, 1, ' ', 0)
+	for _, wf := range selected {
+		fmt.Fprintf(w, "  /%s [description]\t- %s\n", wf.Name, wf.Description)
+	}
+	w.Flush()
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Example usage:")
+	for _, wf := range selected {
+		fmt.Fprintf(out, "  %s\n", wf.Command())
+	}
 
 	return nil
 }
 
-func installPromptFiles(promptsDir string) error {
-	// Get feat template content
-	featContent, err := templates.PromptFiles.ReadFile("prompts/feat.md")
+// installInto installs the selected workflows into the repository at root
+// and returns the operations performed along with the workflows. Project
+// templates in root can add or override workflows. In dry-run mode it prints
+// the plan instead. Conflicts are resolved by policy, asking ask when the
+// policy is to prompt.
+func installInto(cmd *cobra.Command, root string, policy installer.Policy, ask installer.AskFunc) ([]installer.Operation, []templates.Workflow, error) {
+	selected, err := selectWorkflows(root, installOnly, installExclude)
 	if err != nil {
-		return fmt.Errorf("failed to read feat template: %w", err)
+		return nil, nil, err
 	}
 
-	// Write feat.prompt.md
-	featPath := filepath.Join(promptsDir, "feat.prompt.md")
-	if err := os.WriteFile(featPath, featContent, 0644); err != nil {
-		return fmt.Errorf("failed to write feat prompt file: %w", err)
+	// Work out every file the install should produce
+	files, err := installFiles(root, selected)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Get fix template content
-	fixContent, err := templates.PromptFiles.ReadFile("prompts/fix.md")
+	manifest, err := installer.LoadManifest(root)
 	if err != nil {
-		return fmt.Errorf("failed to read fix template: %w", err)
+		return nil, nil, err
 	}
 
-	// Write fix.prompt.md
-	fixPath := filepath.Join(promptsDir, "fix.prompt.md")
-	if err := os.WriteFile(fixPath, fixContent, 0644); err != nil {
-		return fmt.Errorf("failed to write fix prompt file: %w", err)
+	// Compare against what is already on disk
+	ops, err := installer.Plan(root, files, manifest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to plan installation: %w", err)
+	}
+	stale, err := staleScopedInstructions(root, files, manifest)
+	if err != nil {
+		return nil, nil, err
+	}
+	ops = append(ops, stale...)
+
+	if installDryRun {
+		// Show conflicts as plain overwrites rather than prompting for them
+		if policy != installer.PolicyPrompt {
+			if ops, err = installer.Resolve(ops, policy, nil, now()); err != nil {
+				return nil, nil, err
+			}
+		}
+		printDryRun(cmd.OutOrStdout(), ops)
+		return ops, selected, nil
 	}
 
-	// Get refactor template content
-	refactorContent, err := templates.PromptFiles.ReadFile("prompts/refactor.md")
+	ops, err = installer.Resolve(ops, policy, ask, now())
 	if err != nil {
-		return fmt.Errorf("failed to read refactor template: %w", err)
+		return nil, nil, err
 	}
 
-	// Write refactor.prompt.md
-	refactorPath := filepath.Join(promptsDir, "refactor.prompt.md")
-	if err := os.WriteFile(refactorPath, refactorContent, 0644); err != nil {
-		return fmt.Errorf("failed to write refactor prompt file: %w", err)
+	if err := installer.Apply(root, ops); err != nil {
+		return nil, nil, fmt.Errorf("failed to install files: %w", err)
 	}
 
-	// Get instructions template content
-	instructionsContent, err := templates.PromptFiles.ReadFile("prompts/instructions.md")
+	// Record what was written so later commands can detect local edits
+	manifest.Record(ops, version.Version)
+	if err := manifest.Save(root); err != nil {
+		return nil, nil, err
+	}
+
+	return ops, selected, nil
+}
+
+// installRecursively installs into every git repository beneath root and
+// prints a summary table. A failure in one repository does not stop the
+// others; it is reported in the table and returned at the end.
+func installRecursively(cmd *cobra.Command, root string, policy installer.Policy, ask installer.AskFunc) error {
+	out := cmd.OutOrStdout()
+
+	repos, err := installer.FindRepositories(root)
 	if err != nil {
-		return fmt.Errorf("failed to read instructions template: %w", err)
+		return err
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no git repositories found beneath %s", root)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tCREATED\tUPDATED\tUNCHANGED\tSKIPPED\tRESULT")
+
+	failed := 0
+	for _, repo := range repos {
+		if installDryRun {
+			fmt.Fprintf(out, "==> %s\n", repo)
+		}
+
+		ops, _, err := installInto(cmd, repo, policy, ask)
+		if err != nil {
+			failed++
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\terror: %v\n", repo, err)
+			continue
+		}
+
+		counts := map[string]int{}
+		for _, op := range ops {
+			counts[resultLabel(op.Action)]++
+		}
+		result := "ok"
+		if installDryRun {
+			result = "dry run"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\n", repo,
+			counts["Created:"], counts["Updated:"]+counts["Merged:"], counts["Unchanged:"], counts["Skipped:"], result)
 	}
 
-	// Write instructions.prompt.md
-	instructionsPath := filepath.Join(promptsDir, "instructions.prompt.md")
-	if err := os.WriteFile(instructionsPath, instructionsContent, 0644); err != nil {
-		return fmt.Errorf("failed to write instructions prompt file: %w", err)
+	if installDryRun {
+		fmt.Fprintln(out)
 	}
+	w.Flush()
 
+	if failed > 0 {
+		return fmt.Errorf("installation failed in %d of %d repositories", failed, len(repos))
+	}
 	return nil
 }
 
-func generateCopilotInstructions() string {
-	return `# GitHub Copilot Instructions for go-agent-kit
+// Install locations, relative to the repository root
+const (
+	instructionsPath = ".github/copilot-instructions.md"
+	promptsDir       = ".github/prompts"
+)
+
+// instructionsTemplate names the generated instructions in the manifest
+const instructionsTemplate = "copilot-instructions"
+
+// installFiles returns every file install writes beneath root for the
+// selected workflows
+func installFiles(root string, selected []templates.Workflow) ([]installer.File, error) {
+	facts, err := project.Scan(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan project: %w", err)
+	}
+
+	instructions, err := instructionsFile(root, generateCopilotInstructions(selected, facts))
+	if err != nil {
+		return nil, err
+	}
+	files := []installer.File{instructions}
+
+	prompts, err := promptFileContents(promptsDir, selected, facts, referenceContext(root))
+	if err != nil {
+		return nil, fmt.Errorf("failed to install prompt files: %w", err)
+	}
+	files = append(files, prompts...)
+
+	scoped, err := scopedInstructionsFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	return append(files, scoped...), nil
+}
+
+// legacyInstructionsHeading starts the copilot-instructions.md written by
+// versions of go-agent-kit that owned the whole file
+const legacyInstructionsHeading = "# GitHub Copilot Instructions for go-agent-kit"
+
+// legacyInstructionsSHA256 is the hash of the copilot-instructions.md those
+// versions wrote, which never changed
+const legacyInstructionsSHA256 = "6a583b490c289760656400f8c59ebec2e4d0a43d5d0281bbac80357bae4967f6"
+
+// instructionsFile places the generated instructions inside the managed block
+// of the existing copilot-instructions.md, leaving the team's own
+// instructions above and below it untouched
+func instructionsFile(root, generated string) (installer.File, error) {
+	current, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(instructionsPath)))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return installer.File{}, fmt.Errorf("failed to read %s: %w", instructionsPath, err)
+	}
+
+	existing := string(current)
+	edited := false
+	if strings.HasPrefix(existing, legacyInstructionsHeading) {
+		// Written whole by an older version without markers. It is ours to
+		// replace only as long as nobody has added to it; otherwise the
+		// conflict policy decides.
+		normalized := strings.ReplaceAll(existing, "\r\n", "\n")
+		edited = installer.HashContent([]byte(normalized)) != legacyInstructionsSHA256
+		existing = ""
+	}
+
+	content, err := installer.UpsertBlock(existing, generated)
+	if err != nil {
+		return installer.File{}, fmt.Errorf("failed to update %s: %w", instructionsPath, err)
+	}
+
+	return installer.File{
+		Path:     instructionsPath,
+		Content:  []byte(content),
+		Template: instructionsTemplate,
+		Block:    true,
+		Edited:   edited,
+	}, nil
+}
+
+// printDryRun lists the planned operations followed by a unified diff of
+// every file that would change
+func printDryRun(out io.Writer, ops []installer.Operation) {
+	fmt.Fprintln(out, "Dry run: no files will be written.")
+	fmt.Fprintln(out)
+	for _, op := range ops {
+		fmt.Fprintf(out, "  %-10s %s\n", op.Action, op.Path)
+	}
+	for _, op := range ops {
+		if d := op.Diff(); d != "" {
+			fmt.Fprintln(out)
+			fmt.Fprint(out, d)
+		}
+	}
+}
+
+// resultLabel describes a completed operation in the install summary
+func resultLabel(action installer.Action) string {
+	switch action {
+	case installer.ActionCreate:
+		return "Created:"
+	case installer.ActionOverwrite, installer.ActionUpdate, installer.ActionBackup:
+		return "Updated:"
+	case installer.ActionMerge:
+		return "Merged:"
+	case installer.ActionSkip:
+		return "Skipped:"
+	case installer.ActionRemove:
+		return "Removed:"
+	default:
+		return "Unchanged:"
+	}
+}
+
+// askConflict returns an AskFunc that asks on the command's input and output
+// streams how to handle each conflicting file
+func askConflict(cmd *cobra.Command) installer.AskFunc {
+	in := bufio.NewReader(cmd.InOrStdin())
+	out := cmd.OutOrStdout()
+
+	return func(op installer.Operation) (installer.Policy, error) {
+		// Only a file shared through a managed block can be merged
+		choices, answers := "[s]kip, [o]verwrite, [b]ackup, [d]iff?", "s, o, b or d"
+		if op.Block {
+			choices, answers = "[s]kip, [o]verwrite, [b]ackup, [m]erge, [d]iff?", "s, o, b, m or d"
+		}
+		for {
+			fmt.Fprintf(out, "%s already exists and differs. %s ", filepath.ToSlash(op.Path), choices)
+			answer, err := in.ReadString('\n')
+			if err != nil && answer == "" {
+				// No more input: keep the user's file
+				fmt.Fprintln(out)
+				return installer.PolicySkip, nil
+			}
+
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "s", "skip", "":
+				return installer.PolicySkip, nil
+			case "o", "overwrite":
+				return installer.PolicyOverwrite, nil
+			case "b", "backup":
+				return installer.PolicyBackup, nil
+			case "m", "merge":
+				if op.Block {
+					return installer.PolicyMerge, nil
+				}
+				fmt.Fprintf(out, "Please answer %s.\n", answers)
+			case "d", "diff":
+				fmt.Fprint(out, op.Diff())
+			default:
+				fmt.Fprintf(out, "Please answer %s.\n", answers)
+			}
+		}
+	}
+}
+
+// promptFileContents renders the selected workflow templates as Copilot
+// prompt files located in dir, stating the detected project facts and
+// referring to the reference files in refs
+func promptFileContents(dir string, selected []templates.Workflow, facts project.Facts, refs templates.Context) ([]installer.File, error) {
+	var files []installer.File
+	for _, w := range selected {
+		ctx := projectContext(templates.CopilotContext(w.Placeholder), facts)
+		ctx.RepoMapPath = refs.RepoMapPath
+		ctx.GoSymbolsPath = refs.GoSymbolsPath
+		content, err := w.RenderPromptFile(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s template: %w", w.Name, err)
+		}
+
+		files = append(files, installer.File{
+			Path:     path.Join(dir, w.Name+".prompt.md"),
+			Content:  []byte(content),
+			Template: w.Name,
+		})
+	}
+
+	return files, nil
+}
+
+// referenceContext points prompts at the reference files the map and index
+// commands wrote beneath root, if they exist
+func referenceContext(root string) templates.Context {
+	var ctx templates.Context
+	if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(repoMapPath))); err == nil {
+		ctx.RepoMapPath = repoMapPath
+	}
+	if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(goSymbolsPath))); err == nil {
+		ctx.GoSymbolsPath = goSymbolsPath
+	}
+	return ctx
+}
+
+// writeVerificationCommands documents how to verify changes in the project
+// in dir ("" for the repository root), if any commands were detected
+func writeVerificationCommands(b *strings.Builder, facts project.Facts, dir string) {
+	if len(facts.Commands) == 0 && facts.TestCommand == "" {
+		return
+	}
+
+	b.WriteString("## Verifying Changes\n\n")
+	if dir == "" {
+		b.WriteString("Run these commands before considering a change complete, and fix any failures:\n")
+	} else {
+		fmt.Fprintf(b, "Run these commands from `%s/` before considering a change complete, and fix any failures:\n", dir)
+	}
+	if len(facts.Commands) > 0 {
+		for _, c := range facts.Commands {
+			fmt.Fprintf(b, "- `%s` (%s, defined in %s)\n", c.Run, c.Kind, c.Source)
+		}
+	} else {
+		for _, c := range []struct{ kind, run string }{
+			{project.KindBuild, facts.BuildCommand},
+			{project.KindTest, facts.TestCommand},
+			{project.KindLint, facts.LintCommand},
+		} {
+			if c.run != "" {
+				fmt.Fprintf(b, "- `%s` (%s)\n", c.run, c.kind)
+			}
+		}
+	}
+	b.WriteString("\n")
+}
+
+// writeCodingStandards documents the rules the project's linter and
+// formatter configurations enforce, if any were found
+func writeCodingStandards(b *strings.Builder, facts project.Facts) {
+	if len(facts.Conventions) == 0 {
+		return
+	}
+
+	b.WriteString("## Coding Standards\n\n")
+	b.WriteString("Follow the rules this project's tooling enforces:\n")
+	for _, c := range facts.Conventions {
+		fmt.Fprintf(b, "\n**%s** (`%s`)\n", c.Tool, c.Source)
+		for _, rule := range c.Rules {
+			fmt.Fprintf(b, "- %s\n", rule)
+		}
+	}
+	b.WriteString("\n")
+}
+
+// projectContext adds the detected project facts to a template context
+func projectContext(ctx templates.Context, facts project.Facts) templates.Context {
+	ctx.Language = facts.Language
+	ctx.Frameworks = facts.Frameworks
+	ctx.ModulePath = facts.ModulePath
+	ctx.PackageManager = facts.PackageManager
+	ctx.TestCommand = facts.TestCommand
+	ctx.BuildCommand = facts.BuildCommand
+	ctx.LintCommand = facts.LintCommand
+	ctx.SourceDirs = facts.SourceDirs
+	for _, c := range facts.Commands {
+		ctx.Commands = append(ctx.Commands, templates.Command{Kind: c.Kind, Run: c.Run, Source: c.Source})
+	}
+	return ctx
+}
+
+// generateCopilotInstructions documents the selected workflows for the
+// managed block of copilot-instructions.md
+func generateCopilotInstructions(selected []templates.Workflow, facts project.Facts) string {
+	var b strings.Builder
+	b.WriteString(`# GitHub Copilot Instructions for go-agent-kit
 
 This project uses go-agent-kit for structured AI agent workflows. Use the following commands for systematic development:
 
 ## Available Commands
-
-### /feat - Feature Implementation Workflow
-Use this command to implement new features with a structured approach.
-
-**Usage:**
-` + "```" + `
-/feat [description of the feature to implement]
-` + "```" + `
-
-**Examples:**
-- /feat add user authentication system
-- /feat implement REST API with JWT tokens
-- /feat add file upload functionality
-- /feat create admin dashboard
-
-**What it does:**
-Generates a comprehensive 5-stage workflow:
-1. **CODEBASE ANALYSIS** - Detect language, examine patterns, find integration points
-2. **IMPLEMENTATION PLAN** - Plan files, dependencies, and implementation order
-3. **IMPLEMENTATION** - Step-by-step coding with language-specific best practices
-4. **TESTING** - Unit tests, integration tests, and edge cases
-5. **DOCUMENTATION** - Code comments, README updates, and API docs
-
-### /fix - Bug Fix Workflow
-Use this command to systematically diagnose and fix bugs.
-
-**Usage:**
-` + "```" + `
-/fix [description of the bug or issue]
-` + "```" + `
-
-**Examples:**
-- /fix null pointer exception in user service
-- /fix memory leak in background worker
-- /fix authentication not working on mobile
-- /fix database connection timeout errors
-
-**What it does:**
-Generates a systematic 5-stage debugging workflow:
-1. **DIAGNOSIS** - Understand, locate, reproduce, and analyze the issue
-2. **FIX STRATEGY** - Plan the fix approach and assess impact
-3. **IMPLEMENTATION** - Apply minimal fix with safety checks
-4. **TESTING** - Verify fix and run regression tests
-5. **DOCUMENTATION** - Document the fix and add preventive measures
-
-### /refactor - Code Refactoring Workflow
-Use this command to systematically improve and refactor existing code.
-
-**Usage:**
-` + "```" + `
-/refactor [description of the refactoring task]
-` + "```" + `
-
-**Examples:**
-- /refactor simplify user authentication logic
-- /refactor extract payment processing into separate service
-- /refactor optimize database query performance
-- /refactor improve error handling patterns
-
-**What it does:**
-Generates a comprehensive 5-stage refactoring workflow:
-1. **CODEBASE ANALYSIS** - Understand current implementation and identify improvements
-2. **REFACTOR PLAN** - Plan refactoring strategy and assess risks
-3. **IMPLEMENTATION** - Apply refactoring techniques systematically
-4. **TESTING** - Verify functionality and performance are maintained
-5. **DOCUMENTATION** - Update docs to reflect architectural changes
-
-## Language-Agnostic Design
+`)
+
+	for _, w := range selected {
+		fmt.Fprintf(&b, "\n### /%s - %s\n", w.Name, w.Title)
+		fmt.Fprintf(&b, "%s\n\n", w.Summary)
+		b.WriteString("**Usage:**\n```\n")
+		fmt.Fprintf(&b, "/%s %s\n", w.Name, w.Usage)
+		b.WriteString("```\n\n**Examples:**\n")
+		for _, example := range w.Examples {
+			fmt.Fprintf(&b, "- %s\n", strings.TrimSpace("/"+w.Name+" "+example))
+		}
+		fmt.Fprintf(&b, "\n**What it does:**\n%s\n", w.Overview)
+		for i, st := range w.Stages {
+			fmt.Fprintf(&b, "%d. **%s** - %s\n", i+1, st.Title, st.Summary)
+		}
+	}
+	b.WriteString(`
+`)
+	writeVerificationCommands(&b, facts, "")
+	writeCodingStandards(&b, facts)
+	b.WriteString(`## Language-Agnostic Design
 
 These workflows are designed to work with ANY programming language:
 - **Go** - Follows Go conventions, error patterns, and testing practices
@@ -232,15 +581,27 @@ When you use these commands in GitHub Copilot Chat:
 
 1. Run ` + "`go-agent-kit install`" + ` in your project (already done!)
 2. Open GitHub Copilot Chat
-3. Try: ` + "`/feat add a simple hello world endpoint`" + `
+3. Try: ` + "`" + selected[0].Command() + "`" + `
 4. Follow the generated workflow step by step
 
 ---
 
-*Generated by go-agent-kit - A language-agnostic toolkit for structured AI agent workflows.*`
+*Generated by go-agent-kit - A language-agnostic toolkit for structured AI agent workflows.*`)
+
+	return b.String()
 }
 
 func init() {
 	// Add install command to root command
 	rootCmd.AddCommand(installCmd)
+
+	installCmd.Long = fmt.Sprintf(installCmd.Long, workflowExamples(), strings.Join(availableWorkflows(), ", "))
+
+	installCmd.Flags().BoolVar(&installDryRun, "dry-run", false, "show the planned changes as a unified diff without writing any files")
+	installCmd.Flags().StringVar(&installDir, "dir", installDir, "repository directory to install into")
+	installCmd.Flags().BoolVar(&installRecursive, "recursive", false, "install into every git repository found beneath --dir")
+	installCmd.Flags().StringSliceVar(&installOnly, "only", nil, "install only these workflows (comma-separated: "+strings.Join(availableWorkflows(), ", ")+")")
+	installCmd.Flags().StringSliceVar(&installExclude, "exclude", nil, "do not install these workflows (comma-separated)")
+	installCmd.Flags().BoolVar(&allowUnsigned, "allow-unsigned", false, "install workflows from template packs that are not signed by a trusted key")
+	installCmd.Flags().StringVar(&installConflict, "on-conflict", installConflict, "how to handle existing files that differ: skip, overwrite, backup, prompt or merge")
 }
@@ -2,9 +2,14 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/johnayoung/go-agent-kit/internal/installer"
+	"github.com/johnayoung/go-agent-kit/internal/project"
+	"github.com/johnayoung/go-agent-kit/internal/version"
 	"github.com/spf13/cobra"
 )
 
@@ -104,11 +109,15 @@ func TestInstallCommand(t *testing.T) {
 					}
 
 					contentStr := string(content)
+					if strings.Contains(contentStr, "{{") {
+						t.Errorf("Expected %s to contain no raw template actions", filePath)
+					}
 					if strings.Contains(filePath, "feat") {
 						expectedContent := []string{
 							"Feature Implementation Workflow",
 							"STAGE 1: CODEBASE ANALYSIS",
-							"{{.Description}}",
+							"${input:description:",
+							"mode: 'agent'",
 						}
 						for _, expected := range expectedContent {
 							if !strings.Contains(contentStr, expected) {
@@ -119,7 +128,8 @@ func TestInstallCommand(t *testing.T) {
 						expectedContent := []string{
 							"Bug Fix Workflow",
 							"STAGE 1: DIAGNOSIS",
-							"{{.Description}}",
+							"${input:description:",
+							"mode: 'agent'",
 						}
 						for _, expected := range expectedContent {
 							if !strings.Contains(contentStr, expected) {
@@ -130,7 +140,8 @@ func TestInstallCommand(t *testing.T) {
 						expectedContent := []string{
 							"Code Refactor Workflow",
 							"STAGE 1: CODEBASE ANALYSIS",
-							"{{.Description}}",
+							"${input:description:",
+							"mode: 'agent'",
 						}
 						for _, expected := range expectedContent {
 							if !strings.Contains(contentStr, expected) {
@@ -144,8 +155,744 @@ func TestInstallCommand(t *testing.T) {
 	}
 }
 
+func TestInstallDryRun(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "install-dry-run-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current dir: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	// An existing, hand-written instructions file only gains the managed block
+	if err := os.MkdirAll(".github", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(".github/copilot-instructions.md", []byte("# Our own instructions\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	installDryRun = true
+	installConflict = "overwrite"
+	defer func() {
+		installDryRun = false
+		installConflict = "skip"
+	}()
+
+	var output strings.Builder
+	cmd := &cobra.Command{Use: "install", RunE: runInstall}
+	cmd.SetOut(&output)
+
+	if err := runInstall(cmd, []string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	outputStr := output.String()
+	expectedInText := []string{
+		"Dry run: no files will be written.",
+		"update     .github/copilot-instructions.md",
+		"create     .github/prompts/feat.prompt.md",
+		"--- a/.github/copilot-instructions.md",
+		" # Our own instructions",
+		"+<!-- go-agent-kit:begin v1 -->",
+		"--- /dev/null",
+		"+++ b/.github/prompts/fix.prompt.md",
+	}
+	for _, expected := range expectedInText {
+		if !strings.Contains(outputStr, expected) {
+			t.Errorf("Expected to find '%s' in output", expected)
+		}
+	}
+
+	// Nothing may be written in dry-run mode
+	content, err := os.ReadFile(".github/copilot-instructions.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "# Our own instructions\n" {
+		t.Error("Dry run should not modify existing files")
+	}
+	if _, err := os.Stat(".github/prompts"); !os.IsNotExist(err) {
+		t.Error("Dry run should not create the prompts directory")
+	}
+}
+
+func TestInstallConflictPolicy(t *testing.T) {
+	const userContent = "# Our own feature workflow\n"
+
+	tests := []struct {
+		name            string
+		policy          string
+		input           string
+		expectedContent func(t *testing.T, content string)
+		expectBackup    bool
+		expectedInText  []string
+		expectedError   string
+	}{
+		{
+			name:   "skip keeps the existing file",
+			policy: "skip",
+			expectedContent: func(t *testing.T, content string) {
+				if content != userContent {
+					t.Errorf("Expected existing file to be kept, got %q", content)
+				}
+			},
+			expectedInText: []string{"Skipped: .github/prompts/feat.prompt.md"},
+		},
+		{
+			name:   "overwrite replaces the existing file",
+			policy: "overwrite",
+			expectedContent: func(t *testing.T, content string) {
+				if strings.Contains(content, "Our own feature workflow") {
+					t.Error("Expected existing file to be replaced")
+				}
+			},
+			expectedInText: []string{"Updated: .github/prompts/feat.prompt.md"},
+		},
+		{
+			name:   "backup saves a timestamped copy",
+			policy: "backup",
+			expectedContent: func(t *testing.T, content string) {
+				if strings.Contains(content, "Our own feature workflow") {
+					t.Error("Expected existing file to be replaced")
+				}
+			},
+			expectBackup:   true,
+			expectedInText: []string{"backup: .github/prompts/feat.prompt.md.20240102-030405.bak"},
+		},
+		{
+			name:          "merge refuses a whole prompt file",
+			policy:        "merge",
+			expectedError: "cannot merge .github/prompts/feat.prompt.md",
+		},
+		{
+			name:   "prompt does not offer merge for a whole prompt file",
+			policy: "prompt",
+			input:  "m\ns\n",
+			expectedContent: func(t *testing.T, content string) {
+				if content != userContent {
+					t.Errorf("Expected existing file to be kept, got %q", content)
+				}
+			},
+			expectedInText: []string{
+				"[s]kip, [o]verwrite, [b]ackup, [d]iff?",
+				"Please answer s, o, b or d.",
+				"Skipped: .github/prompts/feat.prompt.md",
+			},
+		},
+		{
+			name:   "prompt asks file by file",
+			policy: "prompt",
+			input:  "d\nb\n",
+			expectedContent: func(t *testing.T, content string) {
+				if strings.Contains(content, "Our own feature workflow") {
+					t.Error("Expected existing file to be replaced")
+				}
+			},
+			expectBackup: true,
+			expectedInText: []string{
+				".github/prompts/feat.prompt.md already exists and differs.",
+				"-# Our own feature workflow",
+				"Updated: .github/prompts/feat.prompt.md",
+			},
+		},
+	}
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current dir: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+	defer func() {
+		now = time.Now
+		installConflict = "skip"
+	}()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir, err := os.MkdirTemp("", "install-conflict-test-*")
+			if err != nil {
+				t.Fatalf("Failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(tempDir)
+
+			if err := os.Chdir(tempDir); err != nil {
+				t.Fatalf("Failed to change to temp dir: %v", err)
+			}
+			if err := os.MkdirAll(".github/prompts", 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(".github/prompts/feat.prompt.md", []byte(userContent), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			installConflict = tt.policy
+
+			var output strings.Builder
+			cmd := &cobra.Command{Use: "install", RunE: runInstall}
+			cmd.SetOut(&output)
+			cmd.SetIn(strings.NewReader(tt.input))
+
+			err = runInstall(cmd, []string{})
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("Expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			content, err := os.ReadFile(".github/prompts/feat.prompt.md")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.expectedContent(t, string(content))
+
+			backup, err := os.ReadFile(".github/prompts/feat.prompt.md.20240102-030405.bak")
+			if tt.expectBackup {
+				if err != nil {
+					t.Errorf("Expected backup file: %v", err)
+				} else if string(backup) != userContent {
+					t.Errorf("Expected backup to hold the original content, got %q", backup)
+				}
+			} else if err == nil {
+				t.Error("Did not expect a backup file")
+			}
+
+			outputStr := output.String()
+			for _, expected := range tt.expectedInText {
+				if !strings.Contains(outputStr, expected) {
+					t.Errorf("Expected to find '%s' in output:\n%s", expected, outputStr)
+				}
+			}
+		})
+	}
+}
+
+func TestInstallRejectsUnknownPolicy(t *testing.T) {
+	installConflict = "clobber"
+	defer func() { installConflict = "skip" }()
+
+	cmd := &cobra.Command{Use: "install", RunE: runInstall}
+	cmd.SetOut(&strings.Builder{})
+
+	err := runInstall(cmd, []string{})
+	if err == nil || !strings.Contains(err.Error(), "unknown conflict policy") {
+		t.Errorf("Expected unknown policy error, got %v", err)
+	}
+}
+
+func TestInstallManagedBlock(t *testing.T) {
+	legacy, err := os.ReadFile(filepath.Join("testdata", "legacy-copilot-instructions.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tempDir, err := os.MkdirTemp("", "install-block-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current dir: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		existing string
+		check    func(t *testing.T, content string)
+	}{
+		{
+			name:     "user instructions around the block are preserved",
+			existing: "# Team rules\n\n<!-- go-agent-kit:begin v1 -->\nstale\n<!-- go-agent-kit:end -->\n\n## More rules\n",
+			check: func(t *testing.T, content string) {
+				if !strings.HasPrefix(content, "# Team rules\n\n<!-- go-agent-kit:begin v1 -->\n") {
+					t.Errorf("Expected content above the block to be preserved, got %q", content[:60])
+				}
+				if !strings.HasSuffix(content, "<!-- go-agent-kit:end -->\n\n## More rules\n") {
+					t.Error("Expected content below the block to be preserved")
+				}
+				if strings.Contains(content, "stale") {
+					t.Error("Expected the managed block to be replaced")
+				}
+			},
+		},
+		{
+			name:     "legacy whole-file instructions are replaced",
+			existing: string(legacy),
+			check: func(t *testing.T, content string) {
+				if !strings.HasPrefix(content, "<!-- go-agent-kit:begin v1 -->\n") {
+					t.Error("Expected legacy content to be replaced by the managed block")
+				}
+				if strings.Count(content, legacyInstructionsHeading) != 1 {
+					t.Error("Expected legacy content to be removed")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.MkdirAll(".github", 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(".github/copilot-instructions.md", []byte(tt.existing), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cmd := &cobra.Command{Use: "install", RunE: runInstall}
+			cmd.SetOut(&strings.Builder{})
+			if err := runInstall(cmd, []string{}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			content, err := os.ReadFile(".github/copilot-instructions.md")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, string(content))
+
+			// Reinstalling is a no-op
+			var output strings.Builder
+			cmd.SetOut(&output)
+			if err := runInstall(cmd, []string{}); err != nil {
+				t.Fatalf("Unexpected error on reinstall: %v", err)
+			}
+			if !strings.Contains(output.String(), "Unchanged: .github/copilot-instructions.md") {
+				t.Errorf("Expected reinstall to leave the file unchanged:\n%s", output.String())
+			}
+		})
+	}
+}
+
+func TestInstallEditedLegacyInstructions(t *testing.T) {
+	legacy, err := os.ReadFile(filepath.Join("testdata", "legacy-copilot-instructions.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := string(legacy) + "\n## Team rules\n\nNever push to main.\n"
+
+	tests := []struct {
+		policy   string
+		expected string
+		backup   bool
+	}{
+		{policy: "skip", expected: "Skipped: .github/copilot-instructions.md"},
+		{policy: "backup", expected: "Updated: .github/copilot-instructions.md (backup: ", backup: true},
+		{policy: "merge", expected: "Merged: .github/copilot-instructions.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			tempDir := t.TempDir()
+			originalDir, err := os.Getwd()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Chdir(originalDir)
+			if err := os.Chdir(tempDir); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(".github", 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(".github/copilot-instructions.md", []byte(edited), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			installConflict = tt.policy
+			defer func() { installConflict = "skip" }()
+
+			var output strings.Builder
+			cmd := &cobra.Command{Use: "install", RunE: runInstall}
+			cmd.SetOut(&output)
+			if err := runInstall(cmd, []string{}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !strings.Contains(output.String(), tt.expected) {
+				t.Errorf("Expected %q in output:\n%s", tt.expected, output.String())
+			}
+
+			content, err := os.ReadFile(".github/copilot-instructions.md")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.policy == "merge" {
+				if !strings.HasPrefix(string(content), edited+"\n<!-- go-agent-kit:begin v1 -->\n") {
+					t.Error("Expected the managed block below the edited instructions")
+				}
+				return
+			}
+			if !tt.backup {
+				if string(content) != edited {
+					t.Error("Expected the edited instructions to be left alone")
+				}
+				return
+			}
+			if !strings.HasPrefix(string(content), "<!-- go-agent-kit:begin v1 -->\n") {
+				t.Error("Expected the instructions to be replaced by the managed block")
+			}
+			backups, _ := filepath.Glob(".github/copilot-instructions.md.*.bak")
+			if len(backups) != 1 {
+				t.Fatalf("Expected one backup, got %v", backups)
+			}
+			saved, err := os.ReadFile(backups[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(saved) != edited {
+				t.Error("Expected the backup to hold the edited instructions")
+			}
+		})
+	}
+}
+
+func TestInstallManifest(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "install-manifest-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current dir: %v", err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+
+	cmd := &cobra.Command{Use: "install", RunE: runInstall}
+	cmd.SetOut(&strings.Builder{})
+	if err := runInstall(cmd, []string{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	manifest, err := installer.LoadManifest(".")
+	if err != nil {
+		t.Fatalf("Failed to load manifest: %v", err)
+	}
+	if manifest.Version != version.Version {
+		t.Errorf("Expected manifest version %s, got %s", version.Version, manifest.Version)
+	}
+
+	expectedEntries := map[string]string{
+		".github/copilot-instructions.md":        installer.KindBlock,
+		".github/prompts/feat.prompt.md":         installer.KindFile,
+		".github/prompts/fix.prompt.md":          installer.KindFile,
+		".github/prompts/refactor.prompt.md":     installer.KindFile,
+		".github/prompts/instructions.prompt.md": installer.KindFile,
+	}
+	for path, kind := range expectedEntries {
+		entry, ok := manifest.Entry(path)
+		if !ok {
+			t.Errorf("Expected manifest entry for %s", path)
+			continue
+		}
+		if entry.Kind != kind {
+			t.Errorf("Expected %s to be recorded as %s, got %s", path, kind, entry.Kind)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !manifest.Unmodified(path, content) {
+			t.Errorf("Expected recorded hash to match %s", path)
+		}
+	}
+
+	// A locally edited prompt file is detected and kept on reinstall
+	if err := os.WriteFile(".github/prompts/feat.prompt.md", []byte("# Tweaked\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var output strings.Builder
+	cmd.SetOut(&output)
+	if err := runInstall(cmd, []string{}); err != nil {
+		t.Fatalf("Unexpected error on reinstall: %v", err)
+	}
+	if !strings.Contains(output.String(), "Skipped: .github/prompts/feat.prompt.md") {
+		t.Errorf("Expected edited file to be skipped:\n%s", output.String())
+	}
+
+	// An unedited prompt file that differs from the recorded hash's source is updated
+	manifest, err = installer.LoadManifest(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := []byte("# Older kit version\n")
+	if err := os.WriteFile(".github/prompts/fix.prompt.md", stale, 0644); err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := manifest.Entry(".github/prompts/fix.prompt.md")
+	entry.SHA256 = installer.HashContent(stale)
+	manifest.Set(entry)
+	if err := manifest.Save("."); err != nil {
+		t.Fatal(err)
+	}
+
+	output.Reset()
+	if err := runInstall(cmd, []string{}); err != nil {
+		t.Fatalf("Unexpected error on reinstall: %v", err)
+	}
+	if !strings.Contains(output.String(), "Updated: .github/prompts/fix.prompt.md") {
+		t.Errorf("Expected unedited file to be updated:\n%s", output.String())
+	}
+}
+
+func TestInstallDir(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "install-dir-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	installDir = tempDir
+	defer func() { installDir = "." }()
+
+	if _, err := runCommand(t, runInstall, nil, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, path := range []string{".github/copilot-instructions.md", ".github/prompts/feat.prompt.md", ".github/.go-agent-kit.lock"} {
+		if _, err := os.Stat(filepath.Join(tempDir, path)); err != nil {
+			t.Errorf("Expected %s to be created in --dir: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(".github/.go-agent-kit.lock"); err == nil {
+		t.Error("Did not expect files in the working directory")
+	}
+}
+
+func TestInstallProjectFacts(t *testing.T) {
+	defer enterTempDir(t)()
+
+	if err := os.WriteFile("go.mod", []byte("module github.com/acme/api\n\ngo 1.22\n\nrequire github.com/go-chi/chi/v5 v5.0.12\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runCommand(t, runInstall, nil, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(".github/prompts/feat.prompt.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"- Language: Go", "- Frameworks: Chi", "- Module: `github.com/acme/api`", "- Test: `go test ./...`"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected %q in feat.prompt.md", expected)
+		}
+	}
+
+	// New facts make the installed prompts outdated
+	if err := os.WriteFile(".golangci.yml", []byte("linters: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	statusExitCode = true
+	defer func() { statusExitCode = false }()
+	if output, err := runCommand(t, runStatus, nil, ""); err == nil {
+		t.Errorf("Expected status to report the prompts as out of date:\n%s", output)
+	}
+}
+
+func TestInstallVerificationCommands(t *testing.T) {
+	defer enterTempDir(t)()
+
+	files := map[string]string{
+		"go.mod":   "module example.com/svc\n",
+		"Makefile": "build:\n\tgo build ./...\n\ntest:\n\tgo test -race ./...\n\nlint:\n\tgolangci-lint run\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := runCommand(t, runInstall, nil, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string][]string{
+		".github/copilot-instructions.md": {"## Verifying Changes", "- `make test` (test, defined in Makefile)"},
+		".github/prompts/fix.prompt.md":   {"- Test: `make test`", "- `make lint` (lint, defined in Makefile)"},
+	}
+	for path, wants := range expected {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("Expected %q in %s", want, path)
+			}
+		}
+	}
+}
+
+func TestInstallCodingStandards(t *testing.T) {
+	defer enterTempDir(t)()
+
+	files := map[string]string{
+		"go.mod":        "module example.com/svc\n",
+		".golangci.yml": "linters:\n  enable: [errcheck, lll]\nlinters-settings:\n  lll:\n    line-length: 120\n",
+		".editorconfig": "[*.go]\nindent_style = tab\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := runCommand(t, runInstall, nil, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(".github/copilot-instructions.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"## Coding Standards",
+		"**golangci-lint** (`.golangci.yml`)\n- Enabled linters: errcheck, lll\n- Maximum line length: 120 (lll)",
+		"**EditorConfig** (`.editorconfig`)\n- `*.go`: indent_style = tab",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected %q in the instructions:\n%s", want, content)
+		}
+	}
+}
+
+func TestInstallRecursive(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "install-recursive-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Two repositories, one of them with a hand-edited prompt file, plus a
+	// plain directory and a dependency directory that must be ignored
+	for _, dir := range []string{"api/.git", "web/.git", "docs", "web/node_modules/dep/.git"} {
+		if err := os.MkdirAll(filepath.Join(tempDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(tempDir, "web/.github/prompts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "web/.github/prompts/fix.prompt.md"), []byte("# Ours\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	installDir = tempDir
+	installRecursive = true
+	defer func() {
+		installDir = "."
+		installRecursive = false
+	}()
+
+	output, err := runCommand(t, runInstall, nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and two repository rows, got:\n%s", output)
+	}
+	expectedRows := []struct {
+		repo   string
+		fields []string
+	}{
+		{filepath.Join(tempDir, "api"), []string{"5", "0", "0", "0", "ok"}},
+		{filepath.Join(tempDir, "web"), []string{"4", "0", "0", "1", "ok"}},
+	}
+	for i, row := range expectedRows {
+		fields := strings.Fields(lines[i+1])
+		if fields[0] != row.repo {
+			t.Errorf("Expected row %d to be %s, got %s", i+1, row.repo, fields[0])
+			continue
+		}
+		if strings.Join(fields[1:], " ") != strings.Join(row.fields, " ") {
+			t.Errorf("Unexpected summary for %s: %v", row.repo, fields[1:])
+		}
+	}
+
+	for _, dir := range []string{"docs", "web/node_modules/dep"} {
+		if _, err := os.Stat(filepath.Join(tempDir, dir, ".github")); err == nil {
+			t.Errorf("Did not expect an install in %s", dir)
+		}
+	}
+}
+
+func TestInstallRecursivePrompt(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "install-recursive-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// A conflict in each repository, answered from one piped input
+	for _, repo := range []string{"api", "web"} {
+		if err := os.MkdirAll(filepath.Join(tempDir, repo, ".git"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(tempDir, repo, ".github/prompts"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tempDir, repo, ".github/prompts/fix.prompt.md"), []byte("# Ours\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	installDir = tempDir
+	installRecursive = true
+	installConflict = "prompt"
+	defer func() {
+		installDir = "."
+		installRecursive = false
+		installConflict = "skip"
+	}()
+
+	if _, err := runCommand(t, runInstall, nil, "s\no\n"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]bool{"api": true, "web": false}
+	for repo, kept := range expected {
+		content, err := os.ReadFile(filepath.Join(tempDir, repo, ".github/prompts/fix.prompt.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (string(content) == "# Ours\n") != kept {
+			t.Errorf("Expected the answer for %s to apply (kept: %v), got:\n%s", repo, kept, content)
+		}
+	}
+}
+
 func TestGenerateCopilotInstructions(t *testing.T) {
-	instructions := generateCopilotInstructions()
+	workflows, err := allWorkflows(".")
+	if err != nil {
+		t.Fatalf("Failed to load workflows: %v", err)
+	}
+	instructions := generateCopilotInstructions(workflows, project.Facts{})
 
 	expectedContent := []string{
 		"GitHub Copilot Instructions for go-agent-kit",
@@ -168,4 +915,12 @@ func TestGenerateCopilotInstructions(t *testing.T) {
 	if len(instructions) < 1000 {
 		t.Error("Generated instructions seem too short")
 	}
+	if strings.Contains(instructions, "## Verifying Changes") {
+		t.Error("Did not expect verification commands without detected facts")
+	}
+
+	instructions = generateCopilotInstructions(workflows, project.Facts{BuildCommand: "cargo build", TestCommand: "cargo test"})
+	if !strings.Contains(instructions, "- `cargo build` (build)\n- `cargo test` (test)\n") {
+		t.Errorf("Expected the detected commands to be documented:\n%s", instructions)
+	}
 }
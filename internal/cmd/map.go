@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/johnayoung/go-agent-kit/internal/repomap"
+	"github.com/spf13/cobra"
+)
+
+// repoMapPath is where the map command writes the repository map
+const repoMapPath = ".github/agent-kit/repo-map.md"
+
+// mapCmd represents the map command
+var mapCmd = &cobra.Command{
+	Use:   "map",
+	Short: "Generate a repository map for the analysis stages",
+	Long: `Map summarizes the layout of the repository so the analysis stage of every
+workflow can start from it instead of rediscovering the project structure:
+
+  - the directory tree, leaving out gitignored files and dependency directories
+  - the entry points: Go main packages, package.json main and bin, Rust
+    binaries and Python scripts
+  - the packages and modules, each with a one-line summary taken from its
+    package documentation, manifest description or README
+
+The map is written to .github/agent-kit/repo-map.md; installed prompt files
+refer to it once it exists (run upgrade after the first map). The tree is
+made shallower and the package list shorter until the map fits --budget.`,
+	RunE: runMap,
+}
+
+var (
+	// mapDir is the --dir repository to map
+	mapDir = "."
+	// mapBudget is the --budget size limit in bytes
+	mapBudget = repomap.DefaultBudget
+	// mapStdout is set by --stdout to print the map instead of writing it
+	mapStdout bool
+)
+
+func runMap(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	root := mapDir
+
+	m, err := repomap.Generate(root)
+	if err != nil {
+		return fmt.Errorf("failed to map repository: %w", err)
+	}
+	content := m.Markdown(mapBudget)
+
+	if mapStdout {
+		fmt.Fprint(out, content)
+		return nil
+	}
+
+	target := filepath.Join(root, filepath.FromSlash(repoMapPath))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(repoMapPath), err)
+	}
+	if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", repoMapPath, err)
+	}
+
+	fmt.Fprintf(out, "✅ Wrote %s (%d entry points, %d packages, %d bytes)\n", repoMapPath, len(m.EntryPoints), len(m.Packages), len(content))
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(mapCmd)
+
+	mapCmd.Flags().StringVar(&mapDir, "dir", mapDir, "repository directory to map")
+	mapCmd.Flags().IntVar(&mapBudget, "budget", mapBudget, "maximum size of the map in bytes")
+	mapCmd.Flags().BoolVar(&mapStdout, "stdout", false, "print the map instead of writing it")
+}
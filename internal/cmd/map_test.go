@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMap(t *testing.T) {
+	defer enterTempDir(t)()
+
+	files := map[string]string{
+		"go.mod":                 "module example.com/svc\n",
+		"cmd/svc/main.go":        "package main\n\nfunc main() {}\n",
+		"internal/api/server.go": "// Package api serves the HTTP API.\npackage api\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mapStdout = true
+	output, err := runCommand(t, runMap, nil, "")
+	mapStdout = false
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{"- `cmd/svc/main.go` (Go main package)", "- `internal/api`: Package api serves the HTTP API."} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the map:\n%s", want, output)
+		}
+	}
+	if _, err := os.Stat(repoMapPath); !os.IsNotExist(err) {
+		t.Error("Did not expect --stdout to write the map")
+	}
+
+	if _, err := runCommand(t, runMap, nil, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, err := os.ReadFile(repoMapPath)
+	if err != nil {
+		t.Fatalf("Expected the map to be written: %v", err)
+	}
+	if string(content) != output {
+		t.Errorf("Expected the written map to match --stdout:\n%s", content)
+	}
+
+	// Installed prompts point at the map
+	if _, err := runCommand(t, runInstall, nil, ""); err != nil {
+		t.Fatalf("Unexpected install error: %v", err)
+	}
+	prompt, err := os.ReadFile(".github/prompts/feat.prompt.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(prompt), "Start from the repository map in `.github/agent-kit/repo-map.md`") {
+		t.Errorf("Expected the prompt to refer to the map:\n%s", prompt)
+	}
+}
@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/johnayoung/go-agent-kit/internal/pack"
+	"github.com/spf13/cobra"
+)
+
+// packCmd represents the pack command
+var packCmd = &cobra.Command{
+	Use:   "pack",
+	Short: "Manage template packs shared through git repositories",
+	Long: `A template pack is a git repository of workflow templates, described by a
+pack.yaml manifest at its root:
+
+  name: acme
+  version: 1.2.0
+  description: ACME engineering workflows
+  workflows: [fix, review]     # templates/fix.md, templates/review.md
+
+Packs added to a project are pinned to an exact commit and content checksum in
+.go-agent-kit/config.yaml and cloned into $XDG_CACHE_HOME/go-agent-kit/packs.
+Their workflows are installed alongside the built-in ones, replacing built-ins
+of the same name; user and project templates still override them.
+
+Prompt files steer an agent that edits your code, so packs must be signed.
+A signed pack has a SHA256SUMS file covering pack.yaml and its templates and
+a SHA256SUMS.sig ed25519 signature of it (see pack keygen and pack sign).
+Packs whose files do not match SHA256SUMS, or whose signature does not verify
+with the key given to pack add --key, are always refused. Unsigned packs are
+refused unless --allow-unsigned is given to pack add, install, upgrade,
+status, render and run.`,
+}
+
+// packAddCmd represents the pack add command
+var packAddCmd = &cobra.Command{
+	Use:   "add <git-url>[@<ref>]",
+	Short: "Fetch a template pack and pin it in the project config",
+	Long: `Add clones a template pack, checks out the given branch, tag or commit,
+verifies its manifest and pins the resolved commit in .go-agent-kit/config.yaml.
+Adding a pack that is already pinned moves it to the new ref.
+
+The pack must be signed by the ed25519 public key given with --key, which is
+pinned too, unless --allow-unsigned is given.
+
+Run install afterwards to install the pack's workflows.`,
+	Example: `  go-agent-kit pack add https://github.com/acme/agent-pack.git@v1.2.0 --key "$(cat acme.pub)"
+  go-agent-kit pack add git@github.com:acme/agent-pack.git@main --allow-unsigned`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPackAdd,
+}
+
+// packListCmd represents the pack list command
+var packListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the template packs pinned by the project",
+	Args:  cobra.NoArgs,
+	RunE:  runPackList,
+}
+
+// packRemoveCmd represents the pack remove command
+var packRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Unpin a template pack from the project",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPackRemove,
+}
+
+// packKeygenCmd represents the pack keygen command
+var packKeygenCmd = &cobra.Command{
+	Use:   "keygen <name>",
+	Short: "Generate an ed25519 key pair for signing template packs",
+	Long: `Keygen writes <name>.key (the private key, keep it secret) and <name>.pub
+(the public key to hand to pack add --key), both base64-encoded.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPackKeygen,
+}
+
+// packSignCmd represents the pack sign command
+var packSignCmd = &cobra.Command{
+	Use:   "sign [pack-dir]",
+	Short: "Write checksums and a signature for a template pack",
+	Long: `Sign verifies the pack in pack-dir (default the current directory), then
+writes SHA256SUMS with the SHA-256 of pack.yaml and every listed template, and
+SHA256SUMS.sig signing it with the private key from --key. Commit both files
+and tag the release.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPackSign,
+}
+
+var (
+	// packDir is the --dir repository whose packs are managed
+	packDir = "."
+	// packKey is the --key public key for pack add, or private key file for pack sign
+	packKey string
+)
+
+func runPackAdd(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	src, err := pack.ParseSource(args[0])
+	if err != nil {
+		return err
+	}
+
+	config, err := pack.LoadConfig(packDir)
+	if err != nil {
+		return err
+	}
+
+	if packKey != "" {
+		if _, err := pack.ParsePublicKey(packKey); err != nil {
+			return err
+		}
+	}
+
+	p, commit, err := pack.Fetch(src, pack.Trust{PublicKey: packKey, AllowUnsigned: allowUnsigned})
+	if err != nil {
+		return fmt.Errorf("failed to add pack %s: %w", src, err)
+	}
+
+	checksum, err := p.Checksum()
+	if err != nil {
+		return err
+	}
+
+	m := p.Manifest
+	config.Set(pack.Pin{
+		Name:      m.Name,
+		URL:       src.URL,
+		Ref:       src.Ref,
+		Commit:    commit,
+		Version:   m.Version,
+		Checksum:  checksum,
+		PublicKey: packKey,
+	})
+	if err := config.Save(packDir); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "✅ Added pack %s %s (%s)\n", m.Name, m.Version, shortCommit(commit))
+	if packKey != "" && p.Authenticate(pack.Trust{PublicKey: packKey}) == nil {
+		fmt.Fprintln(out, "  Signature: verified")
+	} else {
+		fmt.Fprintln(out, "  ⚠️  Unsigned: accepted because of --allow-unsigned")
+	}
+	fmt.Fprintf(out, "  Pinned in: %s\n", pack.ConfigPath)
+	fmt.Fprintln(out, "  Workflows:")
+	for _, name := range m.Workflows {
+		fmt.Fprintf(out, "    /%s\n", name)
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Run 'go-agent-kit install' to install them.")
+	return nil
+}
+
+func runPackList(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	config, err := pack.LoadConfig(packDir)
+	if err != nil {
+		return err
+	}
+	if len(config.Packs) == 0 {
+		fmt.Fprintln(out, "No template packs pinned. Add one with 'go-agent-kit pack add <git-url>@<ref>'.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tREF\tCOMMIT\tSIGNED\tURL")
+	for _, pin := range config.Packs {
+		signed := "yes"
+		if pin.PublicKey == "" {
+			signed = "no"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", pin.Name, pin.Version, pin.Ref, shortCommit(pin.Commit), signed, pin.URL)
+	}
+	return w.Flush()
+}
+
+func runPackRemove(cmd *cobra.Command, args []string) error {
+	config, err := pack.LoadConfig(packDir)
+	if err != nil {
+		return err
+	}
+	if !config.Remove(args[0]) {
+		return fmt.Errorf("pack %s is not pinned in %s", args[0], pack.ConfigPath)
+	}
+	if err := config.Save(packDir); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Removed pack %s from %s. Prompt files it installed are left in place.\n", args[0], pack.ConfigPath)
+	return nil
+}
+
+func runPackKeygen(cmd *cobra.Command, args []string) error {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	name := args[0]
+	for _, path := range []string{name + ".key", name + ".pub"} {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists", path)
+		}
+	}
+	if err := os.WriteFile(name+".key", []byte(pack.EncodeKey(private)+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := os.WriteFile(name+".pub", []byte(pack.EncodeKey(public)+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write public key: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Private key: %s (keep it secret)\n", name+".key")
+	fmt.Fprintf(out, "Public key:  %s\n", name+".pub")
+	fmt.Fprintf(out, "  %s\n", pack.EncodeKey(public))
+	return nil
+}
+
+func runPackSign(cmd *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	if packKey == "" {
+		return fmt.Errorf("--key is required: the private key file written by pack keygen")
+	}
+
+	data, err := os.ReadFile(packKey)
+	if err != nil {
+		return fmt.Errorf("failed to read private key: %w", err)
+	}
+	key, err := pack.ParsePrivateKey(string(data))
+	if err != nil {
+		return err
+	}
+
+	if err := pack.Sign(dir, key); err != nil {
+		return fmt.Errorf("failed to sign pack: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "✅ Signed pack in %s\n", dir)
+	fmt.Fprintf(out, "  Wrote: %s\n", pack.SumsFile)
+	fmt.Fprintf(out, "  Wrote: %s\n", pack.SignatureFile)
+	return nil
+}
+
+// shortCommit abbreviates a commit hash for display
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+func init() {
+	rootCmd.AddCommand(packCmd)
+	packCmd.AddCommand(packAddCmd, packListCmd, packRemoveCmd, packKeygenCmd, packSignCmd)
+
+	packCmd.PersistentFlags().StringVar(&packDir, "dir", packDir, "repository directory whose packs are managed")
+	packAddCmd.Flags().StringVar(&packKey, "key", "", "base64 ed25519 public key the pack must be signed with")
+	packAddCmd.Flags().BoolVar(&allowUnsigned, "allow-unsigned", false, "accept a pack that is not signed by a trusted key")
+	packSignCmd.Flags().StringVar(&packKey, "key", "", "private key file written by pack keygen")
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johnayoung/go-agent-kit/internal/pack"
+	"github.com/johnayoung/go-agent-kit/internal/testutil"
+	"github.com/spf13/cobra"
+)
+
+// newPackRemote commits files to a new bare git repository tagged v1.0.0 and
+// returns its path
+func newPackRemote(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	base := t.TempDir()
+	work := filepath.Join(base, "work")
+	testutil.WriteFiles(t, work, files)
+
+	remote := filepath.Join(base, "remote.git")
+	for _, args := range [][]string{
+		{"init", "--quiet", work},
+		{"-C", work, "add", "-A"},
+		{"-C", work, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "Pack"},
+		{"-C", work, "tag", "v1.0.0"},
+		{"clone", "--quiet", "--bare", work, remote},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	return remote
+}
+
+func TestPackAddAndInstall(t *testing.T) {
+	defer enterTempDir(t)()
+
+	remote := newPackRemote(t, map[string]string{
+		"pack.yaml":           "name: acme\nversion: 1.0.0\nworkflows: [review, fix]\n",
+		"templates/review.md": "---\norder: 50\ntitle: Code Review Workflow\ndescription: Review a change the ACME way\nexamples:\n  - the payments refactor\n---\n# ACME review of {{.Description}}\n",
+		"templates/fix.md":    "# ACME fix process for {{.Description}}\n",
+	})
+
+	// Unsigned packs are refused unless explicitly allowed
+	if _, err := runCommand(t, runPackAdd, []string{remote + "@v1.0.0"}, ""); err == nil || !strings.Contains(err.Error(), "--allow-unsigned") {
+		t.Fatalf("Expected an unsigned pack to be refused, got %v", err)
+	}
+	allowUnsigned = true
+	defer func() { allowUnsigned = false }()
+
+	output, err := runCommand(t, runPackAdd, []string{remote + "@v1.0.0"}, "")
+	if err != nil {
+		t.Fatalf("pack add failed: %v\n%s", err, output)
+	}
+	if !strings.Contains(output, "Added pack acme 1.0.0") || !strings.Contains(output, "/review") {
+		t.Errorf("Unexpected pack add output:\n%s", output)
+	}
+
+	config, err := pack.LoadConfig(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pin, ok := config.Pin("acme")
+	if !ok || pin.URL != remote || pin.Ref != "v1.0.0" || len(pin.Commit) != 40 {
+		t.Errorf("Expected acme to be pinned, got %+v", config.Packs)
+	}
+
+	output, err = runCommand(t, runPackList, nil, "")
+	if err != nil || !strings.Contains(output, "acme") || !strings.Contains(output, pin.Commit[:12]) {
+		t.Errorf("Unexpected pack list output (%v):\n%s", err, output)
+	}
+
+	// A fresh cache, as on a teammate's machine, is filled from the pin, but
+	// the unsigned pack still needs --allow-unsigned
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(t.TempDir(), "cache"))
+	allowUnsigned = false
+	if _, err := runCommand(t, runInstall, nil, ""); err == nil {
+		t.Fatal("Expected install to refuse the unsigned pack")
+	}
+	if _, err := os.Stat(".github/prompts/review.prompt.md"); !os.IsNotExist(err) {
+		t.Error("Did not expect prompt files from the unsigned pack")
+	}
+	allowUnsigned = true
+	if output, err := runCommand(t, runInstall, nil, ""); err != nil {
+		t.Fatalf("install failed: %v\n%s", err, output)
+	}
+
+	expected := map[string]string{
+		".github/prompts/review.prompt.md": "# ACME review of ${input:description",
+		".github/prompts/fix.prompt.md":    "# ACME fix process for ${input:description",
+		".github/prompts/feat.prompt.md":   "Feature Implementation Workflow",
+		".github/copilot-instructions.md":  "### /review - Code Review Workflow",
+	}
+	for path, want := range expected {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Expected %s to be installed: %v", path, err)
+		}
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected %q in %s", want, path)
+		}
+	}
+
+	if _, err := runCommand(t, runPackRemove, []string{"acme"}, ""); err != nil {
+		t.Fatalf("pack remove failed: %v", err)
+	}
+	if _, err := runCommand(t, runPackRemove, []string{"acme"}, ""); err == nil {
+		t.Error("Expected error removing a pack that is not pinned")
+	}
+}
+
+func TestPackSigned(t *testing.T) {
+	defer enterTempDir(t)()
+
+	// Publish a pack signed with a new key
+	packDir := t.TempDir()
+	files := map[string]string{
+		"pack.yaml":           "name: acme\nversion: 1.0.0\nworkflows: [review]\n",
+		"templates/review.md": "---\ntitle: Code Review Workflow\n---\n# ACME review of {{.Description}}\n",
+	}
+	testutil.WriteFiles(t, packDir, files)
+
+	if output, err := runCommand(t, runPackKeygen, []string{"acme"}, ""); err != nil {
+		t.Fatalf("pack keygen failed: %v\n%s", err, output)
+	}
+	if _, err := runCommand(t, runPackKeygen, []string{"acme"}, ""); err == nil {
+		t.Error("Expected keygen to refuse to overwrite an existing key")
+	}
+
+	packKey = "acme.key"
+	output, err := runCommand(t, runPackSign, []string{packDir}, "")
+	packKey = ""
+	if err != nil {
+		t.Fatalf("pack sign failed: %v\n%s", err, output)
+	}
+	for name := range files {
+		content, _ := os.ReadFile(filepath.Join(packDir, filepath.FromSlash(name)))
+		files[name] = string(content)
+	}
+	for _, name := range []string{pack.SumsFile, pack.SignatureFile} {
+		content, err := os.ReadFile(filepath.Join(packDir, name))
+		if err != nil {
+			t.Fatalf("Expected %s to be written: %v", name, err)
+		}
+		files[name] = string(content)
+	}
+	remote := newPackRemote(t, files)
+
+	public, err := os.ReadFile("acme.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	packKey = strings.TrimSpace(string(public))
+	defer func() { packKey = "" }()
+
+	output, err = runCommand(t, runPackAdd, []string{remote + "@v1.0.0"}, "")
+	if err != nil {
+		t.Fatalf("pack add failed: %v\n%s", err, output)
+	}
+	if !strings.Contains(output, "Signature: verified") {
+		t.Errorf("Expected the signature to be verified:\n%s", output)
+	}
+
+	config, err := pack.LoadConfig(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pin, _ := config.Pin("acme"); pin.PublicKey != packKey || pin.Checksum == "" {
+		t.Errorf("Expected the key and checksum to be pinned, got %+v", pin)
+	}
+
+	// Signed packs install without --allow-unsigned
+	if output, err := runCommand(t, runInstall, nil, ""); err != nil {
+		t.Fatalf("install failed: %v\n%s", err, output)
+	}
+	if _, err := os.Stat(".github/prompts/review.prompt.md"); err != nil {
+		t.Errorf("Expected the signed pack's workflow to be installed: %v", err)
+	}
+}
+
+func TestPackAddRejectsInvalidPack(t *testing.T) {
+	defer enterTempDir(t)()
+
+	remote := newPackRemote(t, map[string]string{
+		"pack.yaml": "name: acme\nversion: 1.0.0\nworkflows: [review]\n",
+	})
+
+	allowUnsigned = true
+	defer func() { allowUnsigned = false }()
+
+	if _, err := runCommand(t, runPackAdd, []string{remote + "@v1.0.0"}, ""); err == nil {
+		t.Fatal("Expected pack add to reject a pack missing its templates")
+	}
+	if _, err := os.Stat(pack.ConfigPath); !os.IsNotExist(err) {
+		t.Error("Did not expect the invalid pack to be pinned")
+	}
+}
+
+func TestAllowUnsignedFlag(t *testing.T) {
+	// Every command that loads the pinned packs must be able to accept
+	// unsigned ones
+	for _, c := range []*cobra.Command{packAddCmd, installCmd, upgradeCmd, statusCmd, renderCmd, runStartCmd, runNextCmd, runStatusCmd, runResumeCmd} {
+		if c.Flag("allow-unsigned") == nil {
+			t.Errorf("Expected %s to accept --allow-unsigned", c.CommandPath())
+		}
+	}
+}
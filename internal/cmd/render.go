@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/johnayoung/go-agent-kit/internal/project"
+	"github.com/johnayoung/go-agent-kit/internal/repomap"
+	"github.com/johnayoung/go-agent-kit/internal/symbols"
+	"github.com/johnayoung/go-agent-kit/internal/templates"
+	"github.com/spf13/cobra"
+)
+
+// renderCmd represents the render command
+var renderCmd = &cobra.Command{
+	Use:   "render <workflow> [description]",
+	Short: "Print a fully rendered workflow for any assistant",
+	Long: `Render fills in a workflow template with the description and the detected
+project facts and prints it, for chat UIs without prompt-file support and for
+terminal agents:
+
+  go-agent-kit render feat "add user authentication"
+  go-agent-kit render fix "null pointer in the order handler" --format plain
+  go-agent-kit render refactor "split the store package" -o refactor.md
+
+Formats:
+  markdown  the rendered workflow (default)
+  plain     markdown syntax removed, for pasting into any chat box
+  prompt    a Copilot prompt file with front matter; without a description,
+            Copilot asks for one when the prompt runs
+
+Workflows are divided into "## STAGE n" sections, and agents tend to run
+ahead. --stage renders a single stage, with an instruction to stop when it is
+done; --split writes every stage to its own file in the --output directory:
+
+  go-agent-kit render feat "add search" --stage 2
+  go-agent-kit render feat --split --format prompt -o .github/prompts
+    (writes feat.1-analysis.prompt.md, feat.2-plan.prompt.md, ...)
+
+--map and --symbols embed a freshly generated repository map and Go symbol
+index in the analysis stage instead of referring to the files written by the
+map and index commands.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runRender,
+}
+
+var (
+	// renderDir is the --dir repository whose facts fill in the template
+	renderDir = "."
+	// renderOutput is the --output file, stdout when empty
+	renderOutput string
+	// renderFormat is the --format of the output
+	renderFormat = "markdown"
+	// renderMap is set by --map to embed a repository map
+	renderMap bool
+	// renderSymbols is set by --symbols to embed the Go symbol index
+	renderSymbols bool
+	// renderStage is the --stage to render on its own, 0 for all of them
+	renderStage int
+	// renderSplit is set by --split to write every stage to its own file
+	renderSplit bool
+)
+
+// renderFormats are the supported --format values
+var renderFormats = []string{"markdown", "plain", "prompt"}
+
+func runRender(cmd *cobra.Command, args []string) error {
+	root := renderDir
+	if !contains(renderFormats, renderFormat) {
+		return fmt.Errorf("unknown format %q (available: %s)", renderFormat, strings.Join(renderFormats, ", "))
+	}
+	if renderStage > 0 && renderSplit {
+		return fmt.Errorf("--stage and --split cannot be combined")
+	}
+
+	w, err := lookupWorkflow(root, args[0])
+	if err != nil {
+		return err
+	}
+
+	var ctx templates.Context
+	if len(args) > 1 {
+		ctx, err = renderContext(root, strings.Join(args[1:], " "))
+	} else if renderFormat == "prompt" {
+		// A reusable prompt file: Copilot asks for the description
+		ctx, err = renderContext(root, templates.CopilotInput("description", w.Placeholder))
+	} else {
+		return fmt.Errorf("a description is required unless --format is prompt")
+	}
+	if err != nil {
+		return err
+	}
+
+	body, err := w.Render(ctx)
+	if err != nil {
+		return err
+	}
+	if renderStage == 0 && !renderSplit {
+		return writeRendered(cmd, renderOutput, formatRendered(w, body, ""))
+	}
+
+	doc := templates.SplitStages(body)
+	if len(doc.Stages) == 0 {
+		return fmt.Errorf("workflow %s has no \"## STAGE n\" headings to split at", w.Name)
+	}
+	if renderStage > 0 {
+		content, err := doc.StagePrompt(renderStage, ctx.Description)
+		if err != nil {
+			return err
+		}
+		s, _ := doc.Stage(renderStage)
+		return writeRendered(cmd, renderOutput, formatRendered(w, content, stageLabel(s, len(doc.Stages))))
+	}
+	return writeStages(cmd, w, doc, ctx.Description)
+}
+
+// formatRendered converts a rendered workflow, or one stage of it, to the
+// --format
+func formatRendered(w templates.Workflow, content, stage string) string {
+	switch renderFormat {
+	case "plain":
+		return templates.Plain(content)
+	case "prompt":
+		fm := w.FrontMatter()
+		if stage != "" {
+			fm.Description += " (" + stage + ")"
+		}
+		return fm.String() + "\n" + content
+	}
+	return content
+}
+
+// stageLabel describes a stage for prompt file descriptions, e.g.
+// "stage 1 of 5: CODEBASE ANALYSIS"
+func stageLabel(s templates.StageSection, total int) string {
+	return fmt.Sprintf("stage %d of %d: %s", s.Number, total, s.Title)
+}
+
+// stageFileName names the file holding one stage of a workflow, e.g.
+// feat.1-analysis.prompt.md
+func stageFileName(w templates.Workflow, s templates.StageSection) string {
+	ext := ".md"
+	switch renderFormat {
+	case "prompt":
+		ext = ".prompt.md"
+	case "plain":
+		ext = ".txt"
+	}
+	return fmt.Sprintf("%s.%d-%s%s", w.Name, s.Number, s.Slug(), ext)
+}
+
+// writeStages writes every stage of a workflow to its own file in the
+// --output directory
+func writeStages(cmd *cobra.Command, w templates.Workflow, doc templates.Document, task string) error {
+	dir := renderOutput
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	for _, s := range doc.Stages {
+		content, err := doc.StagePrompt(s.Number, task)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, stageFileName(w, s))
+		if err := os.WriteFile(target, []byte(formatRendered(w, content, stageLabel(s, len(doc.Stages)))), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "✅ Wrote %s\n", target)
+	}
+	return nil
+}
+
+// lookupWorkflow finds a workflow available to root by name
+func lookupWorkflow(root, name string) (templates.Workflow, error) {
+	workflows, err := allWorkflows(root)
+	if err != nil {
+		return templates.Workflow{}, err
+	}
+	for _, w := range workflows {
+		if w.Name == name {
+			return w, nil
+		}
+	}
+	return templates.Workflow{}, fmt.Errorf("unknown workflow %q (available: %s)", name, strings.Join(workflowNames(workflows), ", "))
+}
+
+// renderContext describes the project at root for rendering a workflow
+// outside of Copilot, embedding the repository map and symbol index when
+// --map and --symbols ask for them
+func renderContext(root, description string) (templates.Context, error) {
+	facts, err := project.Scan(root)
+	if err != nil {
+		return templates.Context{}, fmt.Errorf("failed to scan project: %w", err)
+	}
+	ctx := projectContext(templates.Context{Description: description}, facts)
+	refs := referenceContext(root)
+	ctx.RepoMapPath = refs.RepoMapPath
+	ctx.GoSymbolsPath = refs.GoSymbolsPath
+
+	if renderMap {
+		m, err := repomap.Generate(root)
+		if err != nil {
+			return templates.Context{}, fmt.Errorf("failed to map repository: %w", err)
+		}
+		ctx.RepoMap = strings.TrimSpace(m.Section(repomap.DefaultBudget))
+	}
+	if renderSymbols {
+		ix, err := symbols.Build(root)
+		if err != nil {
+			return templates.Context{}, fmt.Errorf("failed to index Go packages: %w", err)
+		}
+		if len(ix.Packages) > 0 {
+			ctx.GoSymbols = strings.TrimSpace(ix.Section(symbols.DefaultBudget))
+		}
+	}
+	return ctx, nil
+}
+
+// writeRendered prints content, or writes it to the output file
+func writeRendered(cmd *cobra.Command, output, content string) error {
+	if output == "" {
+		fmt.Fprint(cmd.OutOrStdout(), content)
+		return nil
+	}
+	if dir := filepath.Dir(output); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+	if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "✅ Wrote %s\n", output)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(renderCmd)
+
+	renderCmd.Flags().StringVar(&renderDir, "dir", renderDir, "repository directory to detect project facts in")
+	renderCmd.Flags().StringVarP(&renderOutput, "output", "o", "", "write to this file (the directory with --split) instead of stdout")
+	renderCmd.Flags().StringVar(&renderFormat, "format", renderFormat, "output format: "+strings.Join(renderFormats, ", "))
+	renderCmd.Flags().BoolVar(&renderMap, "map", false, "embed a repository map in the analysis stage")
+	renderCmd.Flags().BoolVar(&renderSymbols, "symbols", false, "embed the Go symbol index in the analysis stage")
+	renderCmd.Flags().IntVar(&renderStage, "stage", 0, "render only this stage")
+	renderCmd.Flags().BoolVar(&renderSplit, "split", false, "write every stage to its own file")
+	renderCmd.Flags().BoolVar(&allowUnsigned, "allow-unsigned", false, "render workflows from template packs that are not signed by a trusted key")
+}
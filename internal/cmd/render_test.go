@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name             string
+		args             []string
+		format           string
+		renderMap        bool
+		expectedError    bool
+		expectedInText   []string
+		unexpectedInText []string
+	}{
+		{
+			name:   "markdown with detected facts",
+			args:   []string{"feat", "add", "user", "authentication"},
+			format: "markdown",
+			expectedInText: []string{
+				"You are analyzing this codebase to implement: add user authentication",
+				"- Language: Go",
+				"- Module: `example.com/svc`",
+			},
+			unexpectedInText: []string{"${input:", "mode: 'agent'"},
+		},
+		{
+			name:             "plain",
+			args:             []string{"fix", "crash on startup"},
+			format:           "plain",
+			expectedInText:   []string{"STAGE 1: DIAGNOSIS", "- Module: example.com/svc"},
+			unexpectedInText: []string{"## ", "**", "@workspace"},
+		},
+		{
+			name:           "prompt file",
+			args:           []string{"refactor", "split the store"},
+			format:         "prompt",
+			expectedInText: []string{"mode: 'agent'", "to refactor: split the store"},
+		},
+		{
+			name:           "embedded repository map",
+			args:           []string{"feat", "add exports"},
+			format:         "markdown",
+			renderMap:      true,
+			expectedInText: []string{"**Repository map**", "- `cmd/svc/main.go` (Go main package)"},
+		},
+		{
+			name:          "unknown workflow",
+			args:          []string{"deploy", "prod"},
+			format:        "markdown",
+			expectedError: true,
+		},
+		{
+			name:          "unknown format",
+			args:          []string{"feat", "x"},
+			format:        "html",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer enterTempDir(t)()
+			files := map[string]string{
+				"go.mod":          "module example.com/svc\n",
+				"cmd/svc/main.go": "package main\n\nfunc main() {}\n",
+			}
+			for name, content := range files {
+				if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			renderFormat = tt.format
+			renderMap = tt.renderMap
+			defer func() {
+				renderFormat = "markdown"
+				renderMap = false
+			}()
+
+			output, err := runCommand(t, runRender, tt.args, "")
+			if tt.expectedError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, want := range tt.expectedInText {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected %q in output:\n%s", want, output)
+				}
+			}
+			for _, unwanted := range tt.unexpectedInText {
+				if strings.Contains(output, unwanted) {
+					t.Errorf("Did not expect %q in output:\n%s", unwanted, output)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderOutputFile(t *testing.T) {
+	defer enterTempDir(t)()
+
+	renderOutput = "prompts/feat.md"
+	defer func() { renderOutput = "" }()
+
+	output, err := runCommand(t, runRender, []string{"feat", "add search"}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "Wrote prompts/feat.md") {
+		t.Errorf("Expected a confirmation:\n%s", output)
+	}
+	content, err := os.ReadFile("prompts/feat.md")
+	if err != nil {
+		t.Fatalf("Expected the output file: %v", err)
+	}
+	if !strings.Contains(string(content), "to implement: add search") {
+		t.Errorf("Expected the rendered workflow in the file:\n%s", content)
+	}
+}
+
+func TestRenderStage(t *testing.T) {
+	defer enterTempDir(t)()
+
+	renderStage = 2
+	defer func() { renderStage = 0 }()
+
+	output, err := runCommand(t, runRender, []string{"feat", "add search"}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{"**Task:** add search", "## STAGE 2: IMPLEMENTATION PLAN", "This is stage 2 of 5."} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "## STAGE 1") || strings.Contains(output, "## STAGE 3") {
+		t.Errorf("Expected only stage 2:\n%s", output)
+	}
+
+	renderStage = 9
+	if _, err := runCommand(t, runRender, []string{"feat", "add search"}, ""); err == nil {
+		t.Error("Expected an error for a missing stage")
+	}
+}
+
+func TestRenderSplit(t *testing.T) {
+	defer enterTempDir(t)()
+
+	renderSplit = true
+	renderFormat = "prompt"
+	renderOutput = ".github/prompts"
+	defer func() {
+		renderSplit = false
+		renderFormat = "markdown"
+		renderOutput = ""
+	}()
+
+	// Without a description the prompt files ask for one
+	if _, err := runCommand(t, runRender, []string{"feat"}, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"feat.1-analysis.prompt.md", "feat.2-plan.prompt.md", "feat.3-implementation.prompt.md", "feat.4-testing.prompt.md", "feat.5-documentation.prompt.md"}
+	for i, name := range expected {
+		content, err := os.ReadFile(filepath.Join(".github/prompts", name))
+		if err != nil {
+			t.Fatalf("Expected %s: %v", name, err)
+		}
+		if !strings.Contains(string(content), "description: 'Implement a new feature with a structured 5-stage workflow (stage ") {
+			t.Errorf("Expected the stage in the description of %s:\n%s", name, content)
+		}
+		if !strings.Contains(string(content), "${input:description:") {
+			t.Errorf("Expected %s to ask for the description", name)
+		}
+		if i > 0 && strings.Contains(string(content), "## STAGE 1") {
+			t.Errorf("Expected %s to hold a single stage", name)
+		}
+	}
+
+	renderFormat = "markdown"
+	if _, err := runCommand(t, runRender, []string{"feat"}, ""); err == nil {
+		t.Error("Expected an error without a description")
+	}
+}
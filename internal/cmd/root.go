@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/johnayoung/go-agent-kit/internal/version"
 	"github.com/spf13/cobra"
 )
 
@@ -8,8 +12,9 @@ var rootCmd = &cobra.Command{
 	Use:   "go-agent-kit",
 	Short: "Install GitHub Copilot integration for structured AI workflows",
 	Long: `go-agent-kit installs GitHub Copilot integration files that enable structured AI agent workflows.
-After installation, use /refactor and /instructions commands directly in GitHub Copilot Chat.
+After installation, use the %s commands directly in GitHub Copilot Chat.
 Works with any programming language or framework.`,
+	Version: version.Version,
 }
 
 func Execute() error {
@@ -18,4 +23,9 @@ func Execute() error {
 
 func init() {
 	// Root command doesn't need any flags for our simple use case
+	commands := availableWorkflows()
+	for i, name := range commands {
+		commands[i] = "/" + name
+	}
+	rootCmd.Long = fmt.Sprintf(rootCmd.Long, strings.Join(commands, ", "))
 }
@@ -0,0 +1,552 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/johnayoung/go-agent-kit/internal/pack"
+	"github.com/johnayoung/go-agent-kit/internal/project"
+	"github.com/johnayoung/go-agent-kit/internal/run"
+	"github.com/johnayoung/go-agent-kit/internal/templates"
+	"github.com/spf13/cobra"
+)
+
+// runCmd represents the run command
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Follow a workflow stage by stage across chat sessions",
+	Long: `Run follows a workflow one stage at a time and remembers where it got to, so
+long features survive chat resets. Each run is recorded in
+.agent-kit/runs/<id>/: the workflow, the description, the current stage, and
+the output of every completed stage.
+
+  go-agent-kit run start feat "add user authentication"   # prints stage 1
+  go-agent-kit run next < analysis.md                      # records stage 1, prints stage 2
+  go-agent-kit run status                                  # where the run stands
+  go-agent-kit run resume                                  # reprint the current stage in a new chat
+
+Every stage prompt after the first embeds the outputs of the stages before
+it, so a fresh chat session starts with the analysis and plan already done.
+
+Stages can have gates: commands that must pass before the run moves past
+them. The built-in workflows build after IMPLEMENTATION and test and lint
+after TESTING, using the commands detected in the project; the gates key of
+.go-agent-kit/config.yaml replaces them per workflow and stage.`,
+}
+
+// runStartCmd represents the run start command
+var runStartCmd = &cobra.Command{
+	Use:   "start <workflow> <description>",
+	Short: "Start a run and print the first stage prompt",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runRunStart,
+}
+
+// runNextCmd represents the run next command
+var runNextCmd = &cobra.Command{
+	Use:   "next",
+	Short: "Record the current stage's output and print the next stage prompt",
+	Long: `Next records the output of the current stage, read from stdin (paste it and
+press Ctrl-D) or from the --from file, and prints the prompt for the next
+stage with the outputs of all completed stages embedded.
+
+If the stage has gates, next runs them first. When one fails, the run stays
+at the stage and next prints a prompt to fix the failures instead; run next
+again once they are fixed (the recorded output is kept unless you pass a new
+one).`,
+	Args: cobra.NoArgs,
+	RunE: runRunNext,
+}
+
+// runStatusCmd represents the run status command
+var runStatusCmd = &cobra.Command{
+	Use:   "status [id]",
+	Short: "Show the progress of a run, or list the runs",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runRunStatus,
+}
+
+// runResumeCmd represents the run resume command
+var runResumeCmd = &cobra.Command{
+	Use:   "resume [id]",
+	Short: "Make a run current and print its current stage prompt",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runRunResume,
+}
+
+var (
+	// runDir is the --dir repository holding the runs
+	runDir = "."
+	// runFrom is the --from file holding the output of the current stage
+	runFrom string
+	// runNow is the clock, replaced in tests
+	runNow = time.Now
+	// runSkipGates is the --skip-gates flag to advance without running gates
+	runSkipGates bool
+	// runGateTimeout is the --gate-timeout limit for each gate command
+	runGateTimeout = 10 * time.Minute
+)
+
+func runRunStart(cmd *cobra.Command, args []string) error {
+	root := runDir
+	description := strings.Join(args[1:], " ")
+
+	w, err := lookupWorkflow(root, args[0])
+	if err != nil {
+		return err
+	}
+	doc, err := renderStages(root, w, description)
+	if err != nil {
+		return err
+	}
+
+	var stages []run.Stage
+	for _, s := range doc.Stages {
+		stages = append(stages, run.Stage{Number: s.Number, Title: s.Title, Slug: s.Slug()})
+	}
+	r, err := run.New(w.Name, description, stages, runNow())
+	if err != nil {
+		return err
+	}
+	if err := r.Save(root); err != nil {
+		return err
+	}
+	if err := run.SetCurrent(root, r.ID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "✅ Started run %s\n\n", r.ID)
+	return printStagePrompt(cmd, root, r)
+}
+
+func runRunNext(cmd *cobra.Command, args []string) error {
+	root := runDir
+	r, err := currentRun(root, "")
+	if err != nil {
+		return err
+	}
+	if r.Done() {
+		return fmt.Errorf("run %s is already complete", r.ID)
+	}
+	stage, err := currentStage(r)
+	if err != nil {
+		return err
+	}
+
+	output, err := readStageOutput(cmd, stage)
+	if err != nil {
+		// Rerunning next after fixing failed gates keeps the recorded output
+		recorded, readErr := r.ReadFile(root, stage.File("output"))
+		if readErr != nil || runFrom != "" || !gatesFailed(stage.Gates) {
+			return err
+		}
+		output = recorded
+	}
+	if err := r.WriteFile(root, stage.File("output"), output); err != nil {
+		return err
+	}
+
+	if !runSkipGates {
+		passed, err := checkGates(cmd, root, r, stage)
+		if err != nil {
+			return err
+		}
+		if !passed {
+			return fmt.Errorf("stage %d gates failed; fix them, then run: go-agent-kit run next", stage.Number)
+		}
+	}
+
+	more := r.Advance(runNow())
+	if err := r.Save(root); err != nil {
+		return err
+	}
+	if !more {
+		fmt.Fprintf(cmd.ErrOrStderr(), "✅ Run %s complete: all %d stages done\n", r.ID, len(r.Stages))
+		return nil
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "✅ Recorded stage %d: %s\n\n", stage.Number, stage.Title)
+	return printStagePrompt(cmd, root, r)
+}
+
+func runRunStatus(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	root := runDir
+
+	id := ""
+	if len(args) > 0 {
+		id = args[0]
+	}
+	r, err := run.Current(root)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if r, err = run.Load(root, id); err != nil {
+			return err
+		}
+	}
+	if r == nil {
+		return listRuns(out, root)
+	}
+
+	state := "in progress"
+	if r.Done() {
+		state = "complete"
+	}
+	fmt.Fprintf(out, "Run:         %s (%s)\n", r.ID, state)
+	fmt.Fprintf(out, "Workflow:    %s\n", r.Workflow)
+	fmt.Fprintf(out, "Description: %s\n", r.Description)
+	fmt.Fprintf(out, "Started:     %s\n", r.Created.Format(time.RFC3339))
+	fmt.Fprintf(out, "Updated:     %s\n\n", r.Updated.Format(time.RFC3339))
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STAGE\tTITLE\tSTATUS\tOUTPUT")
+	for _, s := range r.Stages {
+		status, file := "pending", ""
+		switch {
+		case !s.Completed.IsZero():
+			status, file = "done", r.FilePath(s.File("output"))
+		case s.Number == r.Stage && gatesFailed(s.Gates):
+			status, file = "gates failed", r.FilePath(s.File("remediation"))
+		case s.Number == r.Stage:
+			status = "current"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", s.Number, s.Title, status, file)
+	}
+	return tw.Flush()
+}
+
+// listRuns prints every recorded run
+func listRuns(out io.Writer, root string) error {
+	runs, err := run.List(root)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		fmt.Fprintln(out, `No runs yet. Start one with: go-agent-kit run start feat "<description>"`)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tWORKFLOW\tSTAGE\tUPDATED")
+	for _, r := range runs {
+		stage := fmt.Sprintf("%d/%d", r.Stage, len(r.Stages))
+		if r.Done() {
+			stage = "complete"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.ID, r.Workflow, stage, r.Updated.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+func runRunResume(cmd *cobra.Command, args []string) error {
+	root := runDir
+	id := ""
+	if len(args) > 0 {
+		id = args[0]
+	}
+	r, err := currentRun(root, id)
+	if err != nil {
+		return err
+	}
+	if r.Done() {
+		return fmt.Errorf("run %s is already complete", r.ID)
+	}
+	if err := run.SetCurrent(root, r.ID); err != nil {
+		return err
+	}
+
+	stage, err := currentStage(r)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Resuming run %s at stage %d of %d: %s\n\n", r.ID, stage.Number, len(r.Stages), stage.Title)
+	return printStagePrompt(cmd, root, r)
+}
+
+// currentStage returns the stage a run is at, which a hand-edited record may
+// no longer list
+func currentStage(r *run.Run) (*run.Stage, error) {
+	stage, ok := r.Current()
+	if !ok {
+		return nil, fmt.Errorf("run %s: stage %d not found in its record", r.ID, r.Stage)
+	}
+	return stage, nil
+}
+
+// currentRun loads the run with the given ID, or the current run
+func currentRun(root, id string) (*run.Run, error) {
+	if id != "" {
+		return run.Load(root, id)
+	}
+	r, err := run.Current(root)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, fmt.Errorf(`no run in progress (start one with: go-agent-kit run start <workflow> "<description>")`)
+	}
+	return r, nil
+}
+
+// renderStages renders a workflow for the project at root and splits it
+// into stages
+func renderStages(root string, w templates.Workflow, description string) (templates.Document, error) {
+	ctx, err := renderContext(root, description)
+	if err != nil {
+		return templates.Document{}, err
+	}
+	body, err := w.Render(ctx)
+	if err != nil {
+		return templates.Document{}, err
+	}
+	doc := templates.SplitStages(body)
+	if len(doc.Stages) == 0 {
+		return templates.Document{}, fmt.Errorf("workflow %s has no \"## STAGE n\" headings to run stage by stage", w.Name)
+	}
+	return doc, nil
+}
+
+// printStagePrompt renders the current stage of a run with the outputs of
+// the completed stages, saves it in the run directory and prints it
+func printStagePrompt(cmd *cobra.Command, root string, r *run.Run) error {
+	w, err := lookupWorkflow(root, r.Workflow)
+	if err != nil {
+		return err
+	}
+	doc, err := renderStages(root, w, r.Description)
+	if err != nil {
+		return err
+	}
+
+	gates, err := runGates(root, w, doc)
+	if err != nil {
+		return err
+	}
+	for i := range doc.Stages {
+		doc.Stages[i].Gates = gates[doc.Stages[i].Number]
+	}
+
+	var earlier []templates.StageOutput
+	for _, s := range r.Stages {
+		if s.Completed.IsZero() {
+			continue
+		}
+		text, err := r.ReadFile(root, s.File("output"))
+		if err != nil {
+			return err
+		}
+		earlier = append(earlier, templates.StageOutput{Number: s.Number, Title: s.Title, Text: text})
+	}
+
+	prompt, err := doc.StagePrompt(r.Stage, r.Description, earlier...)
+	if err != nil {
+		return err
+	}
+	stage, err := currentStage(r)
+	if err != nil {
+		return err
+	}
+	if err := r.WriteFile(root, stage.File("prompt"), prompt); err != nil {
+		return err
+	}
+
+	fmt.Fprint(cmd.OutOrStdout(), prompt)
+	fmt.Fprintf(cmd.ErrOrStderr(), "\nSaved to %s. When the stage is done, record its output with: go-agent-kit run next\n", r.FilePath(stage.File("prompt")))
+	return nil
+}
+
+// runGates resolves the gate commands of every stage of a rendered workflow,
+// by the stage numbers of its "## STAGE n" headings: the project
+// configuration replaces the gates of the workflow's front matter, and gate
+// kinds become the commands detected in the project. Kinds the project has
+// no command for are left out.
+func runGates(root string, w templates.Workflow, doc templates.Document) (map[int][]string, error) {
+	config, err := pack.LoadConfig(root)
+	if err != nil {
+		return nil, err
+	}
+	facts, err := project.Scan(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan project: %w", err)
+	}
+
+	// The front matter names stages by title; the body decides their numbers
+	gates := make(map[int][]string)
+	for _, st := range w.Stages {
+		if len(st.Gates) == 0 {
+			continue
+		}
+		number := 0
+		for _, section := range doc.Stages {
+			if strings.EqualFold(section.Title, st.Title) {
+				number = section.Number
+			}
+		}
+		if number == 0 {
+			return nil, fmt.Errorf("workflow %s: stage %q has gates but no \"## STAGE n: %s\" heading", w.Name, st.Title, st.Title)
+		}
+		var commands []string
+		for _, kind := range st.Gates {
+			if command := gateCommand(kind, facts); command != "" {
+				commands = append(commands, command)
+			}
+		}
+		gates[number] = commands
+	}
+	// Only the project's own config may run command lines; templates name
+	// kinds, since they can come from packs
+	for n, entries := range config.Gates[w.Name] {
+		var commands []string
+		for _, entry := range entries {
+			command := entry
+			if contains(templates.GateKinds, entry) {
+				command = gateCommand(entry, facts)
+			}
+			if command != "" {
+				commands = append(commands, command)
+			}
+		}
+		gates[n] = commands
+	}
+	return gates, nil
+}
+
+// gateCommand resolves a gate kind (build, test, lint, format or check) to
+// the project's own command of that kind, or the default detected for its
+// language. It returns "" when the project has none or for anything else.
+func gateCommand(kind string, facts project.Facts) string {
+	if !contains(templates.GateKinds, kind) {
+		return ""
+	}
+	for _, c := range facts.Commands {
+		if c.Kind == kind {
+			return c.Run
+		}
+	}
+	switch kind {
+	case "build":
+		return facts.BuildCommand
+	case "test":
+		return facts.TestCommand
+	case "lint":
+		return facts.LintCommand
+	}
+	return ""
+}
+
+// checkGates runs the gates of the current stage, records the results and
+// their output in the run, and prints a remediation prompt when any fail
+func checkGates(cmd *cobra.Command, root string, r *run.Run, stage *run.Stage) (bool, error) {
+	w, err := lookupWorkflow(root, r.Workflow)
+	if err != nil {
+		return false, err
+	}
+	doc, err := renderStages(root, w, r.Description)
+	if err != nil {
+		return false, err
+	}
+	gates, err := runGates(root, w, doc)
+	if err != nil {
+		return false, err
+	}
+	commands := gates[stage.Number]
+	if len(commands) == 0 {
+		stage.Gates = nil
+		return true, nil
+	}
+
+	errOut := cmd.ErrOrStderr()
+	fmt.Fprintf(errOut, "Running stage %d gates:\n", stage.Number)
+	for _, c := range commands {
+		fmt.Fprintf(errOut, "  $ %s\n", c)
+	}
+	results := run.RunGates(root, commands, runGateTimeout)
+
+	var log strings.Builder
+	var failures []templates.GateFailure
+	for _, g := range results {
+		mark := "✅"
+		if !g.Passed() {
+			mark = "❌"
+			failures = append(failures, templates.GateFailure{Command: g.Command, ExitCode: g.ExitCode, Output: g.Output})
+		}
+		fmt.Fprintf(errOut, "  %s %s (%s)\n", mark, g.Command, g.Duration)
+		fmt.Fprintf(&log, "## `%s`\n\nExit code %d after %s.\n\n```text\n%s```\n\n", g.Command, g.ExitCode, g.Duration, strings.TrimRight(g.Output, "\n")+"\n")
+	}
+
+	stage.Gates = results
+	r.Updated = runNow()
+	if err := r.WriteFile(root, stage.File("gates"), log.String()); err != nil {
+		return false, err
+	}
+	if err := r.Save(root); err != nil {
+		return false, err
+	}
+	if len(failures) == 0 {
+		return true, nil
+	}
+
+	prompt, err := doc.RemediationPrompt(stage.Number, r.Description, failures)
+	if err != nil {
+		return false, err
+	}
+	if err := r.WriteFile(root, stage.File("remediation"), prompt); err != nil {
+		return false, err
+	}
+	fmt.Fprintln(errOut)
+	fmt.Fprint(cmd.OutOrStdout(), prompt)
+	fmt.Fprintf(errOut, "\nSaved to %s. Full gate output: %s\n", r.FilePath(stage.File("remediation")), r.FilePath(stage.File("gates")))
+	return false, nil
+}
+
+// gatesFailed reports whether any of the latest gate results failed
+func gatesFailed(results []run.GateResult) bool {
+	for _, g := range results {
+		if !g.Passed() {
+			return true
+		}
+	}
+	return false
+}
+
+// readStageOutput reads the output of a stage from the --from file or stdin
+func readStageOutput(cmd *cobra.Command, stage *run.Stage) (string, error) {
+	var data []byte
+	var err error
+	if runFrom != "" {
+		data, err = os.ReadFile(runFrom)
+	} else {
+		if in, ok := cmd.InOrStdin().(*os.File); ok {
+			if info, statErr := in.Stat(); statErr == nil && info.Mode()&os.ModeCharDevice != 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Paste the output of stage %d (%s), then press Ctrl-D:\n", stage.Number, stage.Title)
+			}
+		}
+		data, err = io.ReadAll(cmd.InOrStdin())
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read the stage output: %w", err)
+	}
+
+	output := strings.TrimSpace(string(data))
+	if output == "" {
+		return "", fmt.Errorf("no output for stage %d: paste it on stdin or pass --from <file>", stage.Number)
+	}
+	return output + "\n", nil
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+	runCmd.AddCommand(runStartCmd, runNextCmd, runStatusCmd, runResumeCmd)
+
+	runCmd.PersistentFlags().StringVar(&runDir, "dir", runDir, "repository directory holding the runs")
+	runCmd.PersistentFlags().BoolVar(&allowUnsigned, "allow-unsigned", false, "run workflows from template packs that are not signed by a trusted key")
+	runNextCmd.Flags().StringVar(&runFrom, "from", "", "read the stage output from this file instead of stdin")
+	runNextCmd.Flags().BoolVar(&runSkipGates, "skip-gates", false, "advance without running the stage gates")
+	runNextCmd.Flags().DurationVar(&runGateTimeout, "gate-timeout", runGateTimeout, "time limit for each gate command")
+}
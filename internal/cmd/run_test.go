@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnayoung/go-agent-kit/internal/project"
+	"github.com/johnayoung/go-agent-kit/internal/templates"
+)
+
+func TestRunWorkflow(t *testing.T) {
+	defer enterTempDir(t)()
+
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	runNow = func() time.Time { return now }
+	defer func() { runNow = time.Now }()
+
+	if _, err := runCommand(t, runRunNext, nil, "output"); err == nil {
+		t.Error("Expected an error without a run")
+	}
+
+	output, err := runCommand(t, runRunStart, []string{"fix", "crash", "on", "startup"}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	const id = "20261017-090000-fix-crash-on-startup"
+	for _, want := range []string{"Started run " + id, "## STAGE 1: DIAGNOSIS", "This is stage 1 of 5."} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+	if _, err := os.Stat(".agent-kit/runs/" + id + "/1-diagnosis.prompt.md"); err != nil {
+		t.Errorf("Expected the stage prompt to be saved: %v", err)
+	}
+
+	// An empty paste is refused
+	if _, err := runCommand(t, runRunNext, nil, "  \n"); err == nil {
+		t.Error("Expected an error for an empty stage output")
+	}
+
+	now = now.Add(time.Hour)
+	output, err = runCommand(t, runRunNext, nil, "The nil map in config.Load causes the crash.\n")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"**Task:** crash on startup",
+		"## Results of Earlier Stages",
+		"### Stage 1: DIAGNOSIS\n\nThe nil map in config.Load causes the crash.\n",
+		"## STAGE 2: FIX STRATEGY",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+
+	output, err = runCommand(t, runRunStatus, nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{"Run:         " + id + " (in progress)", "1      DIAGNOSIS", "done", "2      FIX STRATEGY", "current"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in status:\n%s", want, output)
+		}
+	}
+
+	// A new chat session picks up where the last one stopped
+	output, err = runCommand(t, runRunResume, nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "Resuming run "+id+" at stage 2 of 5") || !strings.Contains(output, "The nil map in config.Load") {
+		t.Errorf("Expected the resumed stage with earlier output:\n%s", output)
+	}
+
+	// Stage outputs can come from a file too
+	if err := os.WriteFile("plan.md", []byte("Initialize the map.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runFrom = "plan.md"
+	_, err = runCommand(t, runRunNext, nil, "")
+	runFrom = ""
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, stage := range []string{"3", "4", "5"} {
+		output, err = runCommand(t, runRunNext, nil, "stage "+stage+" done\n")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if !strings.Contains(output, "complete: all 5 stages done") {
+		t.Errorf("Expected the run to complete:\n%s", output)
+	}
+	if _, err := runCommand(t, runRunNext, nil, "more"); err == nil {
+		t.Error("Expected an error advancing a complete run")
+	}
+
+	// Without a current run in progress, status lists the runs
+	if err := os.Remove(".agent-kit/runs/current"); err != nil {
+		t.Fatal(err)
+	}
+	output, err = runCommand(t, runRunStatus, nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, id) || !strings.Contains(output, "complete") {
+		t.Errorf("Expected the run in the list:\n%s", output)
+	}
+}
+
+func TestRunMissingStage(t *testing.T) {
+	defer enterTempDir(t)()
+
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	runNow = func() time.Time { return now }
+	defer func() { runNow = time.Now }()
+
+	if _, err := runCommand(t, runRunStart, []string{"fix", "crash"}, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A hand-edited record whose current stage is not among its stages
+	record := ".agent-kit/runs/20261017-090000-fix-crash/run.json"
+	data, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), `"stage": 1,`, `"stage": 9,`, 1))
+	if err := os.WriteFile(record, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, command := range map[string]func() error{
+		"next":   func() error { _, err := runCommand(t, runRunNext, nil, "output\n"); return err },
+		"resume": func() error { _, err := runCommand(t, runRunResume, nil, ""); return err },
+	} {
+		err := command()
+		if err == nil || !strings.Contains(err.Error(), "stage 9 not found in its record") {
+			t.Errorf("Expected %s to report the missing stage, got %v", name, err)
+		}
+	}
+}
+
+func TestRunGates(t *testing.T) {
+	defer enterTempDir(t)()
+
+	config := "gates:\n  fix:\n    1: [test -f fixed]\n"
+	if err := os.MkdirAll(".go-agent-kit", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(".go-agent-kit/config.yaml", []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := runCommand(t, runRunStart, []string{"fix", "crash"}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "## Stage Gates") || !strings.Contains(output, "- `test -f fixed`") {
+		t.Errorf("Expected the stage prompt to list its gates:\n%s", output)
+	}
+
+	// A failing gate keeps the run at the stage and asks for a fix
+	output, err = runCommand(t, runRunNext, nil, "The config is never loaded.\n")
+	if err == nil {
+		t.Fatal("Expected an error for a failing gate")
+	}
+	for _, want := range []string{"  $ test -f fixed\n", "❌ test -f fixed", "## Fix Failing Gates: Stage 1 (DIAGNOSIS)", "### `test -f fixed` (exit code 1)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+	output, err = runCommand(t, runRunStatus, nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "gates failed") || !strings.Contains(output, "1-diagnosis.remediation.md") {
+		t.Errorf("Expected the failed gates in status:\n%s", output)
+	}
+
+	// Once fixed, next reruns the gates and keeps the recorded output
+	if err := os.WriteFile("fixed", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	output, err = runCommand(t, runRunNext, nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n%s", err, output)
+	}
+	for _, want := range []string{"✅ test -f fixed", "### Stage 1: DIAGNOSIS\n\nThe config is never loaded.\n", "## STAGE 2: FIX STRATEGY"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestGateCommand(t *testing.T) {
+	facts := project.Facts{
+		TestCommand: "go test ./...",
+		LintCommand: "go vet ./...",
+		Commands:    []project.Command{{Kind: "test", Run: "make test", Source: "Makefile"}},
+	}
+
+	tests := []struct {
+		gate     string
+		expected string
+	}{
+		{"test", "make test"},
+		{"lint", "go vet ./..."},
+		{"build", ""},
+		{"format", ""},
+		{"./scripts/check.sh", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.gate, func(t *testing.T) {
+			if got := gateCommand(tt.gate, facts); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRunGatesByHeading(t *testing.T) {
+	defer enterTempDir(t)()
+	if err := os.WriteFile("go.mod", []byte("module example.com/app\n\ngo 1.22\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := templates.Workflow{Name: "ship", Stages: []templates.Stage{
+		{Title: "Plan"},
+		{Title: "Build", Gates: []string{"build"}},
+		{Title: "Verify", Gates: []string{"test"}},
+	}}
+	// The body numbers the stages, whatever order the front matter lists
+	doc := templates.Document{Stages: []templates.StageSection{
+		{Number: 1, Title: "PLAN"},
+		{Number: 2, Title: "VERIFY"},
+		{Number: 3, Title: "BUILD"},
+	}}
+
+	gates, err := runGates(".", w, doc)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(gates[1]) != 0 || strings.Join(gates[2], ",") != "go test ./..." || strings.Join(gates[3], ",") != "go build ./..." {
+		t.Errorf("Expected gates keyed by the body's stage numbers, got %v", gates)
+	}
+
+	// A gated stage the body has no heading for
+	doc.Stages = doc.Stages[:2]
+	if _, err := runGates(".", w, doc); err == nil || !strings.Contains(err.Error(), `stage "Build" has gates`) {
+		t.Errorf("Expected an error for the missing stage heading, got %v", err)
+	}
+}
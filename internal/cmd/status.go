@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"text/tabwriter"
+
+	"github.com/johnayoung/go-agent-kit/internal/installer"
+	"github.com/johnayoung/go-agent-kit/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Report drift between installed files and the embedded templates",
+	Long: `Status inspects .github/prompts/*.prompt.md and copilot-instructions.md and
+classifies each file as:
+
+  up-to-date  matches what this version of go-agent-kit generates
+  outdated    unedited output of an older go-agent-kit version (run upgrade)
+  modified    edited since go-agent-kit installed it
+  missing     not installed (or the managed block was removed)
+  unknown     not recorded in the install manifest, e.g. a team's own prompt
+
+Use --exit-code in CI to fail when any file is missing, outdated or modified.`,
+	RunE: runStatus,
+}
+
+var (
+	// statusExitCode is set by --exit-code to fail unless everything is up to date
+	statusExitCode bool
+	// statusJSON is set by --json for machine-readable output
+	statusJSON bool
+	// statusDir is the --dir repository to inspect
+	statusDir = "."
+)
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	root := statusDir
+
+	manifest, err := installer.LoadManifest(root)
+	if err != nil {
+		return err
+	}
+
+	installed, err := installedWorkflows(root, manifest)
+	if err != nil {
+		return err
+	}
+
+	files, err := installFiles(root, installed)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := installer.Status(root, files, manifest, path.Join(promptsDir, "*.prompt.md"))
+	if err != nil {
+		return fmt.Errorf("failed to inspect installed files: %w", err)
+	}
+
+	if statusJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		report := struct {
+			Version   string                 `json:"version"`
+			Installed string                 `json:"installed,omitempty"`
+			Files     []installer.FileStatus `json:"files"`
+		}{version.Version, manifest.Version, statuses}
+		if err := enc.Encode(report); err != nil {
+			return fmt.Errorf("failed to encode status: %w", err)
+		}
+	} else {
+		if manifest.Version != "" {
+			fmt.Fprintf(out, "Installed by go-agent-kit %s (this is %s)\n\n", manifest.Version, version.Version)
+		} else {
+			fmt.Fprintf(out, "No install manifest found (this is go-agent-kit %s)\n\n", version.Version)
+		}
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FILE\tSTATUS\tDETAIL")
+		for _, s := range statuses {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", s.Path, s.State, s.Detail)
+		}
+		w.Flush()
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, installer.Summary(statuses))
+	}
+
+	if statusExitCode {
+		stale := 0
+		for _, s := range statuses {
+			switch s.State {
+			case installer.StateMissing, installer.StateOutdated, installer.StateModified:
+				stale++
+			}
+		}
+		if stale > 0 {
+			return fmt.Errorf("%d file(s) are not up to date", stale)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().StringVar(&statusDir, "dir", statusDir, "repository directory to inspect")
+	statusCmd.Flags().BoolVar(&statusExitCode, "exit-code", false, "exit non-zero if any file is missing, outdated or modified")
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "print the report as JSON")
+	statusCmd.Flags().BoolVar(&allowUnsigned, "allow-unsigned", false, "compare against template packs that are not signed by a trusted key")
+}
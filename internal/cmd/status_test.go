@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/johnayoung/go-agent-kit/internal/installer"
+)
+
+func TestStatus(t *testing.T) {
+	defer enterTempDir(t)()
+
+	statusExitCode = true
+	defer func() { statusExitCode = false }()
+
+	// Nothing installed yet
+	output, err := runCommand(t, runStatus, nil, "")
+	if err == nil {
+		t.Error("Expected --exit-code to fail when files are missing")
+	}
+	if !strings.Contains(output, "5 missing") {
+		t.Errorf("Expected every file to be missing:\n%s", output)
+	}
+
+	if _, err := runCommand(t, runInstall, nil, ""); err != nil {
+		t.Fatalf("Unexpected install error: %v", err)
+	}
+
+	output, err = runCommand(t, runStatus, nil, "")
+	if err != nil {
+		t.Errorf("Expected a fresh install to be up to date: %v\n%s", err, output)
+	}
+	if !strings.Contains(output, "5 up-to-date") {
+		t.Errorf("Expected every file to be up to date:\n%s", output)
+	}
+
+	// Introduce drift of every kind
+	if err := os.WriteFile(".github/prompts/feat.prompt.md", []byte("# Tweaked\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fixContent, err := os.ReadFile(".github/prompts/fix.prompt.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldFix := strings.Replace(string(fixContent), "# Bug Fix Workflow", "# Old Bug Fix Workflow", 1)
+	simulateOldInstall(t, ".github/prompts/fix.prompt.md", oldFix, oldFix)
+	if err := os.Remove(".github/prompts/refactor.prompt.md"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(".github/prompts/deploy.prompt.md", []byte("# Our deploy workflow\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	statusJSON = true
+	defer func() { statusJSON = false }()
+
+	output, err = runCommand(t, runStatus, nil, "")
+	if err == nil || !strings.Contains(err.Error(), "3 file(s) are not up to date") {
+		t.Errorf("Expected --exit-code failure for 3 files, got %v", err)
+	}
+
+	var report struct {
+		Files []installer.FileStatus `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v\n%s", err, output)
+	}
+
+	expected := map[string]installer.State{
+		".github/copilot-instructions.md":        installer.StateUpToDate,
+		".github/prompts/feat.prompt.md":         installer.StateModified,
+		".github/prompts/fix.prompt.md":          installer.StateOutdated,
+		".github/prompts/refactor.prompt.md":     installer.StateMissing,
+		".github/prompts/instructions.prompt.md": installer.StateUpToDate,
+		".github/prompts/deploy.prompt.md":       installer.StateUnknown,
+	}
+	if len(report.Files) != len(expected) {
+		t.Errorf("Expected %d files in report, got %d", len(expected), len(report.Files))
+	}
+	for _, s := range report.Files {
+		if s.State != expected[s.Path] {
+			t.Errorf("Expected %s to be %s, got %s (%s)", s.Path, expected[s.Path], s.State, s.Detail)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/johnayoung/go-agent-kit/internal/installer"
+	"github.com/johnayoung/go-agent-kit/internal/project"
+)
+
+// instructionsDir holds path-scoped instructions, one file per sub-project
+const instructionsDir = ".github/instructions"
+
+// scopedInstructionsTemplate names the generated path-scoped instructions in
+// the manifest
+const scopedInstructionsTemplate = "scoped-instructions"
+
+// scopedInstructionsFiles returns a path-scoped instructions file for every
+// sub-project of the monorepo at root
+func scopedInstructionsFiles(root string) ([]installer.File, error) {
+	subs, err := project.FindSubProjects(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect sub-projects: %w", err)
+	}
+
+	dirs := make([]string, len(subs))
+	for i, sub := range subs {
+		dirs[i] = sub.Dir
+	}
+	paths := scopedInstructionsPaths(dirs)
+
+	var files []installer.File
+	for _, sub := range subs {
+		files = append(files, installer.File{
+			Path:     paths[sub.Dir],
+			Content:  []byte(generateScopedInstructions(sub)),
+			Template: scopedInstructionsTemplate,
+		})
+	}
+	return files, nil
+}
+
+// staleScopedInstructions plans the removal of the instructions files of
+// sub-projects that have been removed or renamed since the last install
+func staleScopedInstructions(root string, files []installer.File, manifest *installer.Manifest) ([]installer.Operation, error) {
+	ops, err := installer.PlanPrune(root, files, manifest, scopedInstructionsTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan removal of stale instructions: %w", err)
+	}
+	return ops, nil
+}
+
+// scopedInstructionsPath names the instructions file for a sub-project, e.g.
+// services/api becomes .github/instructions/services-api.instructions.md
+func scopedInstructionsPath(dir string) string {
+	return path.Join(instructionsDir, strings.ReplaceAll(dir, "/", "-")+".instructions.md")
+}
+
+// scopedInstructionsPaths names the instructions files of the sub-projects in
+// dirs. Directories that flatten to the same name, such as services/api and
+// services-api, are told apart by a hash of their path.
+func scopedInstructionsPaths(dirs []string) map[string]string {
+	count := make(map[string]int)
+	for _, dir := range dirs {
+		count[scopedInstructionsPath(dir)]++
+	}
+
+	paths := make(map[string]string, len(dirs))
+	for _, dir := range dirs {
+		p := scopedInstructionsPath(dir)
+		if count[p] > 1 {
+			sum := sha256.Sum256([]byte(dir))
+			p = strings.TrimSuffix(p, ".instructions.md") + "-" + hex.EncodeToString(sum[:4]) + ".instructions.md"
+		}
+		paths[dir] = p
+	}
+	return paths
+}
+
+// generateScopedInstructions documents a sub-project for Copilot. The applyTo
+// front matter limits the instructions to files beneath its directory.
+func generateScopedInstructions(sub project.SubProject) string {
+	f := sub.Facts
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "---\napplyTo: '%s/**'\n---\n\n", strings.ReplaceAll(sub.Dir, "'", "''"))
+	fmt.Fprintf(&b, "# %s\n\n", sub.Dir)
+	fmt.Fprintf(&b, "`%s/` is a %s project. These instructions apply to its files and take precedence over the repository-wide instructions.\n\n", sub.Dir, f.Language)
+
+	b.WriteString("## Project\n\n")
+	fmt.Fprintf(&b, "- Language: %s\n", f.Language)
+	if len(f.Frameworks) > 0 {
+		fmt.Fprintf(&b, "- Frameworks: %s\n", strings.Join(f.Frameworks, ", "))
+	}
+	if f.ModulePath != "" {
+		fmt.Fprintf(&b, "- Module: `%s`\n", f.ModulePath)
+	}
+	if f.PackageManager != "" {
+		fmt.Fprintf(&b, "- Package manager: %s\n", f.PackageManager)
+	}
+	if len(f.SourceDirs) > 0 {
+		fmt.Fprintf(&b, "- Source directories: %s\n", strings.Join(f.SourceDirs, ", "))
+	}
+	b.WriteString("\n")
+
+	writeVerificationCommands(&b, f, sub.Dir)
+	writeCodingStandards(&b, f)
+	return b.String()
+}
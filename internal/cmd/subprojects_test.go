@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johnayoung/go-agent-kit/internal/installer"
+)
+
+func TestInstallScopedInstructions(t *testing.T) {
+	defer enterTempDir(t)()
+
+	files := map[string]string{
+		"package.json":               `{"private": true, "workspaces": ["web"]}`,
+		"web/package.json":           `{"name": "web", "scripts": {"test": "vitest"}, "dependencies": {"react": "18"}, "devDependencies": {"typescript": "5"}}`,
+		"services/api/go.mod":        "module example.com/api\n\ngo 1.22\n",
+		"services/api/Makefile":      "test:\n\tgo test ./...\n",
+		"services/api/internal/a.go": "package a\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output, err := runCommand(t, runInstall, nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string][]string{
+		".github/instructions/services-api.instructions.md": {
+			"---\napplyTo: 'services/api/**'\n---\n",
+			"`services/api/` is a Go project",
+			"- Module: `example.com/api`",
+			"- Source directories: internal",
+			"Run these commands from `services/api/`",
+			"- `make test` (test, defined in Makefile)",
+		},
+		".github/instructions/web.instructions.md": {
+			"applyTo: 'web/**'",
+			"- Language: TypeScript",
+			"- Frameworks: React",
+			"- `npm test` (test, defined in package.json)",
+		},
+	}
+	for path, wants := range expected {
+		if !strings.Contains(output, path) {
+			t.Errorf("Expected %s in the install summary", path)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Expected %s to be installed: %v", path, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("Expected %q in %s:\n%s", want, path, content)
+			}
+		}
+	}
+
+	// The scoped files are kit-owned like the prompt files
+	if _, err := runCommand(t, runUninstall, nil, ""); err != nil {
+		t.Fatalf("Uninstall failed: %v", err)
+	}
+	if _, err := os.Stat(".github"); !os.IsNotExist(err) {
+		t.Error("Expected uninstall to remove the scoped instructions")
+	}
+}
+
+func TestInstallPrunesScopedInstructions(t *testing.T) {
+	defer enterTempDir(t)()
+
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("services/api/go.mod", "module example.com/api\n\ngo 1.22\n")
+	write("services/worker/go.mod", "module example.com/worker\n\ngo 1.22\n")
+	if _, err := runCommand(t, runInstall, nil, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// api is renamed to gateway; worker is deleted after its instructions
+	// were edited
+	const (
+		api     = ".github/instructions/services-api.instructions.md"
+		worker  = ".github/instructions/services-worker.instructions.md"
+		gateway = ".github/instructions/services-gateway.instructions.md"
+	)
+	if err := os.Rename("services/api", "services/gateway"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll("services/worker"); err != nil {
+		t.Fatal(err)
+	}
+	write(worker, "# Worker notes\n")
+
+	output, err := runCommand(t, runInstall, nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"Removed: " + api,
+		"Created: " + gateway,
+		"Skipped: " + worker + " (no longer generated, but modified locally; left in place)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+	if _, err := os.Stat(api); !os.IsNotExist(err) {
+		t.Error("Expected the instructions of the renamed sub-project to be removed")
+	}
+	if content, err := os.ReadFile(worker); err != nil || string(content) != "# Worker notes\n" {
+		t.Errorf("Expected the edited instructions to be kept, got %q, %v", content, err)
+	}
+
+	manifest, err := installer.LoadManifest(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{api, worker} {
+		if _, ok := manifest.Entry(path); ok {
+			t.Errorf("Expected %s to be dropped from the manifest", path)
+		}
+	}
+	if _, ok := manifest.Entry(gateway); !ok {
+		t.Errorf("Expected %s in the manifest", gateway)
+	}
+
+	// The kept file is the user's now
+	output, err = runCommand(t, runInstall, nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(output, worker) {
+		t.Errorf("Expected the kept instructions to be left out of later installs:\n%s", output)
+	}
+}
+
+func TestScopedInstructionsPath(t *testing.T) {
+	tests := map[string]string{
+		"web":             ".github/instructions/web.instructions.md",
+		"services/api":    ".github/instructions/services-api.instructions.md",
+		"crates/core/sys": ".github/instructions/crates-core-sys.instructions.md",
+	}
+	for dir, expected := range tests {
+		if got := scopedInstructionsPath(dir); got != expected {
+			t.Errorf("scopedInstructionsPath(%q) = %q, expected %q", dir, got, expected)
+		}
+	}
+}
+
+func TestScopedInstructionsPaths(t *testing.T) {
+	paths := scopedInstructionsPaths([]string{"services/api", "services-api", "web"})
+	if paths["web"] != ".github/instructions/web.instructions.md" {
+		t.Errorf("Expected a sub-project without a clash to keep its name, got %q", paths["web"])
+	}
+	if paths["services/api"] == paths["services-api"] {
+		t.Errorf("Expected services/api and services-api to get different files, both got %q", paths["services/api"])
+	}
+	for dir, p := range paths {
+		if !strings.HasPrefix(p, ".github/instructions/") || !strings.HasSuffix(p, ".instructions.md") {
+			t.Errorf("Unexpected path %q for %s", p, dir)
+		}
+	}
+}
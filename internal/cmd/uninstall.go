@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/johnayoung/go-agent-kit/internal/installer"
+	"github.com/spf13/cobra"
+)
+
+// uninstallCmd represents the uninstall command
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the files installed by go-agent-kit",
+	Long: `Uninstall removes everything go-agent-kit installed, using the install manifest
+(.github/.go-agent-kit.lock) to find it:
+
+  - prompt files it created are deleted
+  - its managed block is stripped from copilot-instructions.md, keeping your own
+    instructions (the file is deleted if nothing else is left)
+  - .github/prompts and .github are removed if they end up empty
+
+Files you have modified since they were installed are never deleted unless
+--force is given.`,
+	RunE: runUninstall,
+}
+
+var (
+	// uninstallForce is set by --force to remove files modified since install
+	uninstallForce bool
+	// uninstallDir is the --dir repository to uninstall from
+	uninstallDir = "."
+)
+
+func runUninstall(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	root := uninstallDir
+
+	manifest, err := installer.LoadManifest(root)
+	if err != nil {
+		return err
+	}
+
+	if len(manifest.Files) == 0 {
+		// Installed before manifests existed: the managed block markers are
+		// the only reliable record of what the kit owns
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(instructionsPath)))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to read %s: %w", instructionsPath, err)
+		}
+		if owned, found := installer.OwnedContent(installer.KindBlock, content); found {
+			// The markers are trusted here, so the block counts as unmodified
+			manifest.Set(installer.ManifestEntry{Path: instructionsPath, Kind: installer.KindBlock, SHA256: installer.HashContent(owned)})
+		}
+	}
+
+	if len(manifest.Files) == 0 {
+		fmt.Fprintln(out, "Nothing to uninstall: no go-agent-kit manifest or managed block found.")
+		return nil
+	}
+
+	removals, err := installer.PlanUninstall(root, manifest)
+	if err != nil {
+		return err
+	}
+
+	var modified []string
+	for _, r := range removals {
+		if r.Modified {
+			modified = append(modified, r.Entry.Path)
+		}
+	}
+	if len(modified) > 0 && !uninstallForce {
+		return fmt.Errorf("refusing to remove files modified since install (use --force to remove them anyway): %s", strings.Join(modified, ", "))
+	}
+
+	if err := installer.ApplyUninstall(root, removals); err != nil {
+		return fmt.Errorf("failed to uninstall: %w", err)
+	}
+
+	fmt.Fprintln(out, "✅ Uninstalled go-agent-kit")
+	fmt.Fprintln(out)
+	for _, r := range removals {
+		switch {
+		case r.Missing:
+			fmt.Fprintf(out, "  Already removed: %s\n", r.Entry.Path)
+		case r.Delete:
+			fmt.Fprintf(out, "  Removed: %s\n", r.Entry.Path)
+		default:
+			fmt.Fprintf(out, "  Stripped managed block: %s\n", r.Entry.Path)
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(uninstallCmd)
+
+	uninstallCmd.Flags().StringVar(&uninstallDir, "dir", uninstallDir, "repository directory to uninstall from")
+	uninstallCmd.Flags().BoolVar(&uninstallForce, "force", false, "also remove files that were modified since install")
+}
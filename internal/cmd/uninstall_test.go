@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// enterTempDir changes into a new temporary directory and returns a function
+// that restores the original working directory and removes the temp dir
+func enterTempDir(t *testing.T) func() {
+	t.Helper()
+
+	tempDir, err := os.MkdirTemp("", "go-agent-kit-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current dir: %v", err)
+	}
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change to temp dir: %v", err)
+	}
+	// Keep user-global templates and packs out of the test
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tempDir, ".config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(tempDir, ".cache"))
+
+	return func() {
+		os.Chdir(originalDir)
+		os.RemoveAll(tempDir)
+	}
+}
+
+// runCommand runs a RunE function with fresh output and input streams
+func runCommand(t *testing.T, run func(*cobra.Command, []string) error, args []string, input string) (string, error) {
+	t.Helper()
+
+	var output strings.Builder
+	cmd := &cobra.Command{Use: "test", RunE: run}
+	cmd.SetOut(&output)
+	cmd.SetErr(&output)
+	cmd.SetIn(strings.NewReader(input))
+
+	err := run(cmd, args)
+	return output.String(), err
+}
+
+func TestUninstall(t *testing.T) {
+	defer enterTempDir(t)()
+
+	const teamInstructions = "# Team instructions\n\nUse tabs.\n"
+	if err := os.MkdirAll(".github", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(".github/copilot-instructions.md", []byte(teamInstructions), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runCommand(t, runInstall, nil, ""); err != nil {
+		t.Fatalf("Unexpected install error: %v", err)
+	}
+
+	output, err := runCommand(t, runUninstall, nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedInText := []string{
+		"Uninstalled go-agent-kit",
+		"Removed: .github/prompts/feat.prompt.md",
+		"Stripped managed block: .github/copilot-instructions.md",
+	}
+	for _, expected := range expectedInText {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected to find '%s' in output:\n%s", expected, output)
+		}
+	}
+
+	content, err := os.ReadFile(".github/copilot-instructions.md")
+	if err != nil {
+		t.Fatalf("Expected team instructions to be kept: %v", err)
+	}
+	if string(content) != teamInstructions {
+		t.Errorf("Expected team instructions to be restored exactly, got %q", content)
+	}
+	for _, path := range []string{".github/prompts", ".github/.go-agent-kit.lock"} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", path)
+		}
+	}
+}
+
+func TestUninstallModifiedFiles(t *testing.T) {
+	defer enterTempDir(t)()
+
+	if _, err := runCommand(t, runInstall, nil, ""); err != nil {
+		t.Fatalf("Unexpected install error: %v", err)
+	}
+	if err := os.WriteFile(".github/prompts/feat.prompt.md", []byte("# Our tweaks\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := runCommand(t, runUninstall, nil, "")
+	if err == nil || !strings.Contains(err.Error(), ".github/prompts/feat.prompt.md") {
+		t.Fatalf("Expected refusal naming the modified file, got %v", err)
+	}
+	// Nothing is removed when uninstall refuses
+	for _, path := range []string{".github/prompts/feat.prompt.md", ".github/prompts/fix.prompt.md", ".github/.go-agent-kit.lock"} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be kept: %v", path, err)
+		}
+	}
+
+	uninstallForce = true
+	defer func() { uninstallForce = false }()
+
+	if _, err := runCommand(t, runUninstall, nil, ""); err != nil {
+		t.Fatalf("Unexpected error with --force: %v", err)
+	}
+	if _, err := os.Stat(".github"); !os.IsNotExist(err) {
+		t.Error("Expected the now-empty .github directory to be removed")
+	}
+}
+
+func TestUninstallWithoutManifest(t *testing.T) {
+	defer enterTempDir(t)()
+
+	if err := os.MkdirAll(".github", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := runCommand(t, runUninstall, nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "Nothing to uninstall") {
+		t.Errorf("Expected nothing to uninstall, got:\n%s", output)
+	}
+
+	// A managed block written before manifests existed is still removed
+	legacy := "# Ours\n\n<!-- go-agent-kit:begin v1 -->\nkit\n<!-- go-agent-kit:end -->\n"
+	if err := os.WriteFile(".github/copilot-instructions.md", []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runCommand(t, runUninstall, nil, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, err := os.ReadFile(".github/copilot-instructions.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "# Ours\n" {
+		t.Errorf("Expected managed block to be stripped, got %q", content)
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/johnayoung/go-agent-kit/internal/installer"
+	"github.com/johnayoung/go-agent-kit/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// upgradeCmd represents the upgrade command
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade installed files to this version, keeping local changes",
+	Long: `Upgrade brings the installed prompt files and copilot-instructions.md up to
+date with the templates embedded in this version of go-agent-kit.
+
+Files you have not edited are simply replaced. Files you have customized are
+three-way merged: the version recorded in .github/.go-agent-kit.lock at install
+time is the common ancestor of your file and the new template, so your changes
+and the template changes are combined. Where both changed the same lines the
+file gets conflict markers (<<<<<<< ||||||| ======= >>>>>>>) for you to resolve.`,
+	RunE: runUpgrade,
+}
+
+var (
+	// upgradeDryRun is set by --dry-run to preview the upgrade without writing
+	upgradeDryRun bool
+	// upgradeDir is the --dir repository to upgrade
+	upgradeDir = "."
+)
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	root := upgradeDir
+
+	manifest, err := installer.LoadManifest(root)
+	if err != nil {
+		return err
+	}
+
+	installed, err := installedWorkflows(root, manifest)
+	if err != nil {
+		return err
+	}
+
+	files, err := installFiles(root, installed)
+	if err != nil {
+		return err
+	}
+
+	ops, err := installer.PlanUpgrade(root, files, manifest, "go-agent-kit "+version.Version)
+	if err != nil {
+		return fmt.Errorf("failed to plan upgrade: %w", err)
+	}
+	stale, err := staleScopedInstructions(root, files, manifest)
+	if err != nil {
+		return err
+	}
+	ops = append(ops, stale...)
+
+	if upgradeDryRun {
+		printDryRun(out, ops)
+		return nil
+	}
+
+	if err := installer.Apply(root, ops); err != nil {
+		return fmt.Errorf("failed to upgrade files: %w", err)
+	}
+
+	manifest.Record(ops, version.Version)
+	if err := manifest.Save(root); err != nil {
+		return err
+	}
+
+	conflicts := 0
+	fmt.Fprintf(out, "Upgraded go-agent-kit files to %s\n", version.Version)
+	fmt.Fprintln(out)
+	for _, op := range ops {
+		switch {
+		case op.Conflict:
+			conflicts++
+			fmt.Fprintf(out, "  Conflict: %s (resolve the conflict markers)\n", op.Path)
+		case op.Action == installer.ActionMerge:
+			fmt.Fprintf(out, "  Merged: %s (kept your changes)\n", op.Path)
+		case op.Action == installer.ActionSkip && op.Orphaned:
+			fmt.Fprintf(out, "  Skipped: %s (no longer generated, but modified locally; left in place)\n", op.Path)
+		case op.Action == installer.ActionSkip:
+			fmt.Fprintf(out, "  Skipped: %s (modified, but no install record to merge against)\n", op.Path)
+		default:
+			fmt.Fprintf(out, "  %s %s\n", resultLabel(op.Action), op.Path)
+		}
+	}
+
+	if conflicts > 0 {
+		return fmt.Errorf("upgrade left conflicts in %d file(s)", conflicts)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+
+	upgradeCmd.Flags().StringVar(&upgradeDir, "dir", upgradeDir, "repository directory to upgrade")
+	upgradeCmd.Flags().BoolVar(&upgradeDryRun, "dry-run", false, "show the upgrade as a unified diff without writing any files")
+	upgradeCmd.Flags().BoolVar(&allowUnsigned, "allow-unsigned", false, "upgrade workflows from template packs that are not signed by a trusted key")
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/johnayoung/go-agent-kit/internal/installer"
+)
+
+// simulateOldInstall rewrites the manifest entry for path as if an older
+// release had installed oldContent, and writes userContent to disk
+func simulateOldInstall(t *testing.T, path, oldContent, userContent string) {
+	t.Helper()
+
+	manifest, err := installer.LoadManifest(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := manifest.Entry(path)
+	if !ok {
+		t.Fatalf("Expected manifest entry for %s", path)
+	}
+	entry.Version = "0.1.0"
+	entry.Content = oldContent
+	entry.SHA256 = installer.HashContent([]byte(oldContent))
+	manifest.Set(entry)
+	if err := manifest.Save("."); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(userContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	defer enterTempDir(t)()
+
+	if _, err := runCommand(t, runInstall, nil, ""); err != nil {
+		t.Fatalf("Unexpected install error: %v", err)
+	}
+
+	const featPath = ".github/prompts/feat.prompt.md"
+	const fixPath = ".github/prompts/fix.prompt.md"
+	current, err := os.ReadFile(featPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newTemplate := string(current)
+
+	// The old release had a different success criteria heading; the team
+	// appended their own section
+	oldTemplate := strings.Replace(newTemplate, "## Success Criteria", "## Done When", 1)
+	simulateOldInstall(t, featPath, oldTemplate, oldTemplate+"\n## Team Rules\n- Always use feature flags\n")
+
+	// fix.prompt.md was never edited, so it is simply replaced
+	fixContent, err := os.ReadFile(fixPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldFix := strings.Replace(string(fixContent), "# Bug Fix Workflow", "# Old Bug Fix Workflow", 1)
+	simulateOldInstall(t, fixPath, oldFix, oldFix)
+
+	output, err := runCommand(t, runUpgrade, nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v\n%s", err, output)
+	}
+
+	expectedInText := []string{
+		"Merged: " + featPath + " (kept your changes)",
+		"Updated: " + fixPath,
+		"Unchanged: .github/prompts/refactor.prompt.md",
+	}
+	for _, expected := range expectedInText {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected to find '%s' in output:\n%s", expected, output)
+		}
+	}
+
+	merged, err := os.ReadFile(featPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(merged), "## Success Criteria") {
+		t.Error("Expected the template change to be applied")
+	}
+	if !strings.Contains(string(merged), "- Always use feature flags") {
+		t.Error("Expected the team's customization to be kept")
+	}
+
+	fixed, err := os.ReadFile(fixPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(fixed) != string(fixContent) {
+		t.Error("Expected the unedited file to match the new template")
+	}
+
+	// The merged file now records the new template as its ancestor
+	manifest, err := installer.LoadManifest(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := manifest.Entry(featPath)
+	if entry.Content != newTemplate {
+		t.Error("Expected the manifest to record the new template as the merge base")
+	}
+	if manifest.Unmodified(featPath, merged) {
+		t.Error("Expected the merged file to still count as locally modified")
+	}
+}
+
+func TestUpgradeConflict(t *testing.T) {
+	defer enterTempDir(t)()
+
+	if _, err := runCommand(t, runInstall, nil, ""); err != nil {
+		t.Fatalf("Unexpected install error: %v", err)
+	}
+
+	const featPath = ".github/prompts/feat.prompt.md"
+	current, err := os.ReadFile(featPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldTemplate := strings.Replace(string(current), "## Success Criteria", "## Done When", 1)
+	userVersion := strings.Replace(string(current), "## Success Criteria", "## Definition of Done", 1)
+	simulateOldInstall(t, featPath, oldTemplate, userVersion)
+
+	output, err := runCommand(t, runUpgrade, nil, "")
+	if err == nil || !strings.Contains(err.Error(), "conflicts in 1 file") {
+		t.Fatalf("Expected conflict error, got %v", err)
+	}
+	if !strings.Contains(output, "Conflict: "+featPath) {
+		t.Errorf("Expected conflict to be reported:\n%s", output)
+	}
+
+	merged, err := os.ReadFile(featPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedMarkers := []string{
+		"<<<<<<< " + featPath + " (your version)\n## Definition of Done\n",
+		"||||||| installed 0.1.0\n## Done When\n",
+		"=======\n## Success Criteria\n>>>>>>> go-agent-kit ",
+	}
+	for _, expected := range expectedMarkers {
+		if !strings.Contains(string(merged), expected) {
+			t.Errorf("Expected to find %q in merged file", expected)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/johnayoung/go-agent-kit/internal/templates"
+	"github.com/spf13/cobra"
+)
+
+// workflowCmd represents the workflow command
+var workflowCmd = &cobra.Command{
+	Use:   "workflow",
+	Short: "Validate and compile declarative workflow definitions",
+	Long: `A workflow definition describes a workflow in YAML instead of markdown: each
+stage has a title, a goal, instructions, the outputs it must report, the gates
+it must pass, the tools it may use and snippets for particular languages.
+
+  go-agent-kit workflow validate migrate.workflow.yaml
+  go-agent-kit workflow compile migrate.workflow.yaml --format chatmode \
+      -o .github/chatmodes/migrate.chatmode.md
+
+Definitions named <name>.workflow.yaml in .go-agent-kit/templates/ (or the
+user template directory) are compiled automatically, so install, render and
+run use them like any other workflow.`,
+}
+
+// workflowValidateCmd represents the workflow validate command
+var workflowValidateCmd = &cobra.Command{
+	Use:   "validate <file>...",
+	Short: "Check workflow definitions and report every problem",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runWorkflowValidate,
+}
+
+// workflowCompileCmd represents the workflow compile command
+var workflowCompileCmd = &cobra.Command{
+	Use:   "compile <file> [description]",
+	Short: "Compile a workflow definition for Copilot or another assistant",
+	Long: `Compile turns a workflow definition into one of these formats, filled in with
+the facts detected in the --dir repository:
+
+  template  a go-agent-kit markdown template, for a template directory or pack
+  prompt    a Copilot prompt file (.prompt.md); Copilot asks for the description
+  chatmode  a Copilot custom chat mode (.chatmode.md)
+  cursor    a Cursor project rule (.mdc)
+  markdown  the rendered workflow for the description
+  plain     the same with markdown syntax removed
+
+Chat modes and Cursor rules take the task from the chat message, so they need
+no description; markdown and plain require one.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runWorkflowCompile,
+}
+
+var (
+	// workflowDir is the --dir repository whose facts fill in the workflow
+	workflowDir = "."
+	// workflowOutput is the --output file, stdout when empty
+	workflowOutput string
+	// workflowFormat is the --format to compile to
+	workflowFormat = "prompt"
+)
+
+// workflowFormats are the supported --format values of workflow compile
+var workflowFormats = []string{"template", "prompt", "chatmode", "cursor", "markdown", "plain"}
+
+// chatTask stands in for the description in formats that take the task from
+// the chat message
+const chatTask = "the task described in the user's message"
+
+func runWorkflowValidate(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	invalid := 0
+	for _, file := range args {
+		spec, err := readSpec(file)
+		if err == nil {
+			err = spec.Validate()
+		}
+		if err != nil {
+			invalid++
+			fmt.Fprintf(out, "❌ %s\n", file)
+			for _, problem := range strings.Split(err.Error(), "\n") {
+				fmt.Fprintf(out, "   - %s\n", problem)
+			}
+			continue
+		}
+		fmt.Fprintf(out, "✅ %s: workflow %s, %d stages\n", file, spec.Name, len(spec.Stages))
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d workflow definitions are invalid", invalid, len(args))
+	}
+	return nil
+}
+
+func runWorkflowCompile(cmd *cobra.Command, args []string) error {
+	root := workflowDir
+	if !contains(workflowFormats, workflowFormat) {
+		return fmt.Errorf("unknown format %q (available: %s)", workflowFormat, strings.Join(workflowFormats, ", "))
+	}
+
+	spec, err := readSpec(args[0])
+	if err != nil {
+		return err
+	}
+	w, err := spec.Workflow()
+	if err != nil {
+		return err
+	}
+	description := strings.Join(args[1:], " ")
+
+	var content string
+	switch workflowFormat {
+	case "template":
+		if description != "" {
+			return fmt.Errorf("--format template keeps the description as a template field; leave it out")
+		}
+		content, err = w.TemplateFile()
+	case "prompt":
+		if description == "" {
+			description = templates.CopilotInput("description", w.Placeholder)
+		}
+		content, err = compileWith(root, description, w.RenderPromptFile)
+	case "chatmode":
+		if description == "" {
+			description = chatTask
+		}
+		content, err = compileWith(root, description, w.RenderChatMode)
+	case "cursor":
+		if description == "" {
+			description = chatTask
+		}
+		content, err = compileWith(root, description, w.Render)
+		content = templates.CursorRule(w.Description, content)
+	default:
+		if description == "" {
+			return fmt.Errorf("a description is required for --format %s", workflowFormat)
+		}
+		content, err = compileWith(root, description, w.Render)
+		if workflowFormat == "plain" {
+			content = templates.Plain(content)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return writeRendered(cmd, workflowOutput, content)
+}
+
+// compileWith renders a workflow with render, for the project at root
+func compileWith(root, description string, render func(templates.Context) (string, error)) (string, error) {
+	ctx, err := renderContext(root, description)
+	if err != nil {
+		return "", err
+	}
+	return render(ctx)
+}
+
+// readSpec reads a workflow definition file, named after the file unless it
+// sets a name
+func readSpec(file string) (templates.Spec, error) {
+	content, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return templates.Spec{}, fmt.Errorf("workflow definition %s does not exist", file)
+	}
+	if err != nil {
+		return templates.Spec{}, fmt.Errorf("failed to read workflow definition: %w", err)
+	}
+
+	name := filepath.Base(file)
+	name = strings.TrimSuffix(name, templates.SpecExt)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	return templates.ParseSpec(name, content)
+}
+
+func init() {
+	rootCmd.AddCommand(workflowCmd)
+	workflowCmd.AddCommand(workflowValidateCmd, workflowCompileCmd)
+
+	workflowCompileCmd.Flags().StringVar(&workflowDir, "dir", workflowDir, "repository directory to detect project facts in")
+	workflowCompileCmd.Flags().StringVarP(&workflowOutput, "output", "o", "", "write to this file instead of stdout")
+	workflowCompileCmd.Flags().StringVar(&workflowFormat, "format", workflowFormat, "output format: "+strings.Join(workflowFormats, ", "))
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const reviewSpec = `title: Code Review Workflow
+description: Review a change before it is merged
+placeholder: Describe the change to review
+stages:
+  - title: CONTEXT
+    goal: Understand what the change is for
+    tools: [codebase, search]
+  - title: REVIEW
+    goal: Find defects and risks
+    outputs:
+      - the defects found, most severe first
+    gates: [test]
+    languages:
+      Go: Check that errors are wrapped with %w.
+`
+
+// writeSpec writes a workflow definition in the current directory
+func writeSpec(t *testing.T, name, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestWorkflowValidate(t *testing.T) {
+	defer enterTempDir(t)()
+	good := writeSpec(t, "review.workflow.yaml", reviewSpec)
+	bad := writeSpec(t, "bad.workflow.yaml", "mode: chat\nstages:\n  - title: A\n")
+
+	output, err := runCommand(t, runWorkflowValidate, []string{good}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "✅ review.workflow.yaml: workflow review, 2 stages") {
+		t.Errorf("Expected the definition to pass:\n%s", output)
+	}
+
+	output, err = runCommand(t, runWorkflowValidate, []string{good, bad, "missing.workflow.yaml"}, "")
+	if err == nil || !strings.Contains(err.Error(), "2 of 3 workflow definitions are invalid") {
+		t.Errorf("Expected two invalid definitions, got %v", err)
+	}
+	for _, want := range []string{
+		"❌ bad.workflow.yaml\n   - description is required",
+		`   - mode "chat" must be one of ask, edit, agent`,
+		"   - stage 1 (A): a goal or instructions are required",
+		"❌ missing.workflow.yaml\n   - workflow definition missing.workflow.yaml does not exist",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output:\n%s", want, output)
+		}
+	}
+}
+
+func TestWorkflowCompile(t *testing.T) {
+	tests := []struct {
+		name             string
+		format           string
+		args             []string
+		expectedError    bool
+		expectedInText   []string
+		unexpectedInText []string
+	}{
+		{
+			name:   "template",
+			format: "template",
+			expectedInText: []string{
+				"---\nname: review\ntitle: Code Review Workflow\n",
+				"    gates:\n      - test\n",
+				"**Task:** {{.Description}}",
+			},
+		},
+		{
+			name:   "prompt file",
+			format: "prompt",
+			expectedInText: []string{
+				"mode: 'agent'\ndescription: 'Review a change before it is merged'\ntools: ['codebase', 'search']\n---\n\n# Code Review Workflow",
+				"**Task:** ${input:description:Describe the change to review}",
+				"- Module: `example.com/svc`",
+				"Check that errors are wrapped with %w.",
+			},
+			unexpectedInText: []string{"**For Go:**"},
+		},
+		{
+			name:             "chat mode",
+			format:           "chatmode",
+			expectedInText:   []string{"---\ndescription: 'Review a change before it is merged'\ntools: ['codebase', 'search']\n---\n", "**Task:** the task described in the user's message"},
+			unexpectedInText: []string{"mode:"},
+		},
+		{
+			name:           "cursor rule",
+			format:         "cursor",
+			expectedInText: []string{"---\ndescription: 'Review a change before it is merged'\nglobs:\nalwaysApply: false\n---\n\n# Code Review Workflow"},
+		},
+		{
+			name:             "plain",
+			format:           "plain",
+			args:             []string{"the retry change"},
+			expectedInText:   []string{"Task: the retry change", "STAGE 2: REVIEW"},
+			unexpectedInText: []string{"**", "## "},
+		},
+		{
+			name:          "markdown without a description",
+			format:        "markdown",
+			expectedError: true,
+		},
+		{
+			name:          "template with a description",
+			format:        "template",
+			args:          []string{"x"},
+			expectedError: true,
+		},
+		{
+			name:          "unknown format",
+			format:        "html",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer enterTempDir(t)()
+			writeSpec(t, "go.mod", "module example.com/svc\n")
+			file := writeSpec(t, "review.workflow.yaml", reviewSpec)
+
+			workflowFormat = tt.format
+			defer func() { workflowFormat = "prompt" }()
+
+			output, err := runCommand(t, runWorkflowCompile, append([]string{file}, tt.args...), "")
+			if tt.expectedError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, want := range tt.expectedInText {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected %q in output:\n%s", want, output)
+				}
+			}
+			for _, unwanted := range tt.unexpectedInText {
+				if strings.Contains(output, unwanted) {
+					t.Errorf("Did not expect %q in output:\n%s", unwanted, output)
+				}
+			}
+		})
+	}
+}
+
+func TestInstallWorkflowDefinition(t *testing.T) {
+	defer enterTempDir(t)()
+	writeSpec(t, ".go-agent-kit/templates/review.workflow.yaml", reviewSpec)
+
+	if _, err := runCommand(t, runInstall, nil, ""); err != nil {
+		t.Fatalf("Unexpected install error: %v", err)
+	}
+	content, err := os.ReadFile(".github/prompts/review.prompt.md")
+	if err != nil {
+		t.Fatalf("Expected a prompt file for the definition: %v", err)
+	}
+	if !strings.Contains(string(content), "## STAGE 2: REVIEW") {
+		t.Errorf("Expected the compiled workflow:\n%s", content)
+	}
+
+	// Runs follow it like a built-in workflow
+	output, err := runCommand(t, runRunStart, []string{"review", "the", "retry", "change"}, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "## STAGE 1: CONTEXT") {
+		t.Errorf("Expected the first stage:\n%s", output)
+	}
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/johnayoung/go-agent-kit/internal/installer"
+	"github.com/johnayoung/go-agent-kit/internal/pack"
+	"github.com/johnayoung/go-agent-kit/internal/templates"
+)
+
+// allowUnsigned is set by --allow-unsigned to accept template packs without a
+// signature from a trusted key
+var allowUnsigned bool
+
+// allWorkflows returns every workflow available to the repository at root:
+// the embedded templates overridden by pinned packs, then user-global and
+// project-local templates
+func allWorkflows(root string) ([]templates.Workflow, error) {
+	packs, err := pack.Layers(root, allowUnsigned)
+	if err != nil {
+		return nil, err
+	}
+
+	registry, err := templates.Load(root, packs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workflow templates: %w", err)
+	}
+	return registry.Workflows(), nil
+}
+
+// workflowNames returns the names of the given workflows
+func workflowNames(list []templates.Workflow) []string {
+	names := make([]string, len(list))
+	for i, w := range list {
+		names[i] = w.Name
+	}
+	return names
+}
+
+// embeddedWorkflows returns the workflows built into the binary, for help text
+func embeddedWorkflows() []templates.Workflow {
+	registry, err := templates.Embedded()
+	if err != nil {
+		return nil
+	}
+	return registry.Workflows()
+}
+
+// availableWorkflows returns the embedded workflow names for help text
+func availableWorkflows() []string {
+	return workflowNames(embeddedWorkflows())
+}
+
+// workflowExamples lists an example slash command per workflow for help text
+func workflowExamples() string {
+	var lines []string
+	for _, w := range embeddedWorkflows() {
+		lines = append(lines, "  "+w.Command())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// selectWorkflows applies --only and --exclude style filters to the workflows
+// available to root, keeping their canonical order. Unknown names are an error.
+func selectWorkflows(root string, only, exclude []string) ([]templates.Workflow, error) {
+	workflows, err := allWorkflows(root)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(workflows))
+	for _, w := range workflows {
+		known[w.Name] = true
+	}
+	for _, name := range append(append([]string{}, only...), exclude...) {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown workflow %q (available: %s)", name, strings.Join(workflowNames(workflows), ", "))
+		}
+	}
+
+	var selected []templates.Workflow
+	for _, w := range workflows {
+		if len(only) > 0 && !contains(only, w.Name) {
+			continue
+		}
+		if contains(exclude, w.Name) {
+			continue
+		}
+		selected = append(selected, w)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no workflows selected")
+	}
+	return selected, nil
+}
+
+// installedWorkflows returns the workflows whose prompt files the manifest
+// records. Repositories without a manifest are assumed to have them all.
+func installedWorkflows(root string, manifest *installer.Manifest) ([]templates.Workflow, error) {
+	workflows, err := allWorkflows(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var installed []templates.Workflow
+	for _, w := range workflows {
+		if _, ok := manifest.Entry(promptPath(w)); ok {
+			installed = append(installed, w)
+		}
+	}
+	if len(installed) == 0 {
+		return workflows, nil
+	}
+	return installed, nil
+}
+
+// promptPath is where a workflow's prompt file is installed
+func promptPath(w templates.Workflow) string {
+	return path.Join(promptsDir, w.Name+".prompt.md")
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
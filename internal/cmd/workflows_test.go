@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSelectWorkflows(t *testing.T) {
+	tests := []struct {
+		name          string
+		only          []string
+		exclude       []string
+		expected      []string
+		expectedError bool
+	}{
+		{
+			name:     "everything by default",
+			expected: []string{"feat", "fix", "refactor", "instructions"},
+		},
+		{
+			name:     "only keeps canonical order",
+			only:     []string{"refactor", "fix"},
+			expected: []string{"fix", "refactor"},
+		},
+		{
+			name:     "exclude",
+			exclude:  []string{"feat", "instructions"},
+			expected: []string{"fix", "refactor"},
+		},
+		{
+			name:     "only and exclude combined",
+			only:     []string{"fix", "refactor"},
+			exclude:  []string{"fix"},
+			expected: []string{"refactor"},
+		},
+		{
+			name:          "unknown workflow",
+			only:          []string{"deploy"},
+			expectedError: true,
+		},
+		{
+			name:          "nothing selected",
+			only:          []string{"fix"},
+			exclude:       []string{"fix"},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := selectWorkflows(".", tt.only, tt.exclude)
+			if tt.expectedError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := workflowNames(selected); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestInstallOnly(t *testing.T) {
+	defer enterTempDir(t)()
+
+	installOnly = []string{"fix", "refactor"}
+	defer func() { installOnly = nil }()
+
+	output, err := runCommand(t, runInstall, nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, path := range []string{".github/prompts/fix.prompt.md", ".github/prompts/refactor.prompt.md"} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be installed: %v", path, err)
+		}
+	}
+	for _, path := range []string{".github/prompts/feat.prompt.md", ".github/prompts/instructions.prompt.md"} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Did not expect %s to be installed", path)
+		}
+	}
+	if strings.Contains(output, "/feat") {
+		t.Errorf("Did not expect /feat in the install summary:\n%s", output)
+	}
+
+	content, err := os.ReadFile(".github/copilot-instructions.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	instructions := string(content)
+	if !strings.Contains(instructions, "### /fix - Bug Fix Workflow") || !strings.Contains(instructions, "### /refactor - Code Refactoring Workflow") {
+		t.Error("Expected the installed commands to be documented")
+	}
+	if strings.Contains(instructions, "### /feat") || strings.Contains(instructions, "### /instructions") {
+		t.Error("Expected only installed commands to be documented")
+	}
+
+	// Later commands only consider the workflows that were installed
+	statusExitCode = true
+	defer func() { statusExitCode = false }()
+	if output, err := runCommand(t, runStatus, nil, ""); err != nil {
+		t.Errorf("Expected a selective install to be up to date: %v\n%s", err, output)
+	}
+}
+
+func TestInstallTemplateOverrides(t *testing.T) {
+	defer enterTempDir(t)()
+
+	files := map[string]string{
+		// Org-wide /fix process, overridden again by this project
+		".config/go-agent-kit/templates/fix.md":  "# Org fix for {{.Description}}\n",
+		".config/go-agent-kit/templates/feat.md": "# Org feature for {{.Description}}\n",
+		".go-agent-kit/templates/fix.md":         "---\ndescription: Fix a bug the project way\n---\n# Project fix for {{.Description}}\n",
+		".go-agent-kit/templates/docs.md":        "---\norder: 50\ntitle: Documentation Workflow\ndescription: Write documentation\nsummary: Use this command to document code.\nexamples:\n  - document the API\n---\n# Docs for {{.Description}}\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output, err := runCommand(t, runInstall, nil, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "/docs document the API") {
+		t.Errorf("Expected the project workflow in the install summary:\n%s", output)
+	}
+
+	expected := map[string][]string{
+		".github/prompts/fix.prompt.md":   {"description: 'Fix a bug the project way'", "# Project fix for ${input:description:"},
+		".github/prompts/feat.prompt.md":  {"# Org feature for ${input:description:"},
+		".github/prompts/docs.prompt.md":  {"description: 'Write documentation'", "# Docs for ${input:description"},
+		".github/copilot-instructions.md": {"### /docs - Documentation Workflow", "### /fix - Bug Fix Workflow"},
+	}
+	for path, wants := range expected {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Expected %s to be installed: %v", path, err)
+		}
+		for _, want := range wants {
+			if !strings.Contains(string(content), want) {
+				t.Errorf("Expected %q in %s:\n%s", want, path, content)
+			}
+		}
+	}
+
+	// status renders the same layers, so the install is up to date
+	statusExitCode = true
+	defer func() { statusExitCode = false }()
+	if output, err := runCommand(t, runStatus, nil, ""); err != nil {
+		t.Errorf("Expected the install to be up to date: %v\n%s", err, output)
+	}
+}
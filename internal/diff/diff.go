@@ -0,0 +1,104 @@
+// Package diff computes line-based differences between text documents.
+package diff
+
+import "strings"
+
+// Op identifies the kind of change an Edit represents
+type Op int
+
+const (
+	// Equal lines appear in both documents
+	Equal Op = iota
+	// Delete lines appear only in the old document
+	Delete
+	// Insert lines appear only in the new document
+	Insert
+)
+
+// Edit is a single line of an edit script turning one document into another
+type Edit struct {
+	Op   Op
+	Line string
+}
+
+// Lines splits text into lines, keeping the trailing newline on each line so
+// that documents can be reassembled exactly
+func Lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Compute returns the shortest edit script turning a into b, based on the
+// longest common subsequence of their lines
+func Compute(a, b []string) []Edit {
+	// Trim the common prefix and suffix so the quadratic table below only
+	// covers the region that actually changed
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]Edit, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		edits = append(edits, Edit{Op: Equal, Line: line})
+	}
+	edits = append(edits, lcsEdits(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, Edit{Op: Equal, Line: line})
+	}
+	return edits
+}
+
+// lcsEdits builds an edit script with a dynamic programming LCS table
+func lcsEdits(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else if table[i+1][j] >= table[i][j+1] {
+				table[i][j] = table[i+1][j]
+			} else {
+				table[i][j] = table[i][j+1]
+			}
+		}
+	}
+
+	var edits []Edit
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, Edit{Op: Equal, Line: a[i]})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			edits = append(edits, Edit{Op: Delete, Line: a[i]})
+			i++
+		default:
+			edits = append(edits, Edit{Op: Insert, Line: b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		edits = append(edits, Edit{Op: Delete, Line: a[i]})
+	}
+	for ; j < m; j++ {
+		edits = append(edits, Edit{Op: Insert, Line: b[j]})
+	}
+	return edits
+}
@@ -0,0 +1,130 @@
+package diff
+
+import (
+	"strings"
+)
+
+// Labels name the three sides of a merge in conflict markers
+type Labels struct {
+	Ours   string
+	Base   string
+	Theirs string
+}
+
+// Merge3 performs a line-based three-way merge of ours and theirs, which both
+// derive from base. Changes made on only one side are applied; regions both
+// sides changed differently are written with diff3-style conflict markers.
+// It reports whether any conflicts were written.
+func Merge3(base, ours, theirs string, labels Labels) (string, bool) {
+	baseLines := Lines(base)
+	oursLines := Lines(ours)
+	theirsLines := Lines(theirs)
+
+	toOurs := matches(baseLines, oursLines)
+	toTheirs := matches(baseLines, theirsLines)
+
+	var b strings.Builder
+	conflicted := false
+	i, j, k := 0, 0, 0
+	for i < len(baseLines) || j < len(oursLines) || k < len(theirsLines) {
+		// A line all three versions share is stable and copied through
+		if i < len(baseLines) && toOurs[i] == j && toTheirs[i] == k {
+			b.WriteString(baseLines[i])
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+
+		// Find the next stable line; everything before it is a changed chunk
+		ni, nj, nk := len(baseLines), len(oursLines), len(theirsLines)
+		for n := i; n < len(baseLines); n++ {
+			if toOurs[n] >= j && toTheirs[n] >= k {
+				ni, nj, nk = n, toOurs[n], toTheirs[n]
+				break
+			}
+		}
+
+		baseChunk := baseLines[i:ni]
+		oursChunk := oursLines[j:nj]
+		theirsChunk := theirsLines[k:nk]
+		switch {
+		case equalLines(oursChunk, baseChunk):
+			writeLines(&b, theirsChunk)
+		case equalLines(theirsChunk, baseChunk), equalLines(oursChunk, theirsChunk):
+			writeLines(&b, oursChunk)
+		default:
+			conflicted = true
+			writeConflict(&b, baseChunk, oursChunk, theirsChunk, labels)
+		}
+		i, j, k = ni, nj, nk
+	}
+
+	return b.String(), conflicted
+}
+
+// matches maps each line of a to the index of the line it is paired with in
+// b by the longest common subsequence, or -1 when it was deleted
+func matches(a, b []string) []int {
+	result := make([]int, len(a))
+	i, j := 0, 0
+	for _, e := range Compute(a, b) {
+		switch e.Op {
+		case Equal:
+			result[i] = j
+			i++
+			j++
+		case Delete:
+			result[i] = -1
+			i++
+		case Insert:
+			j++
+		}
+	}
+	return result
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeLines(b *strings.Builder, lines []string) {
+	for _, line := range lines {
+		b.WriteString(line)
+	}
+}
+
+// writeConflict writes a conflicting region in diff3 style
+func writeConflict(b *strings.Builder, base, ours, theirs []string, labels Labels) {
+	writeMarker(b, "<<<<<<<", labels.Ours)
+	writeSide(b, ours)
+	writeMarker(b, "|||||||", labels.Base)
+	writeSide(b, base)
+	writeMarker(b, "=======", "")
+	writeSide(b, theirs)
+	writeMarker(b, ">>>>>>>", labels.Theirs)
+}
+
+func writeMarker(b *strings.Builder, marker, label string) {
+	b.WriteString(marker)
+	if label != "" {
+		b.WriteString(" ")
+		b.WriteString(label)
+	}
+	b.WriteString("\n")
+}
+
+// writeSide writes one side of a conflict, making sure the marker that
+// follows starts on its own line
+func writeSide(b *strings.Builder, lines []string) {
+	writeLines(b, lines)
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		b.WriteString("\n")
+	}
+}
@@ -0,0 +1,68 @@
+package diff
+
+import "testing"
+
+func TestMerge3(t *testing.T) {
+	labels := Labels{Ours: "ours", Base: "base", Theirs: "theirs"}
+	base := "title\n\none\ntwo\nthree\n\nfooter\n"
+
+	tests := []struct {
+		name             string
+		ours             string
+		theirs           string
+		expected         string
+		expectedConflict bool
+	}{
+		{
+			name:     "unchanged ours takes theirs",
+			ours:     base,
+			theirs:   "title\n\none\n2\nthree\n\nfooter\n",
+			expected: "title\n\none\n2\nthree\n\nfooter\n",
+		},
+		{
+			name:     "unchanged theirs keeps ours",
+			ours:     "title\n\none\ntwo\nthree\n\nour footer\n",
+			theirs:   base,
+			expected: "title\n\none\ntwo\nthree\n\nour footer\n",
+		},
+		{
+			name:     "changes in different places are combined",
+			ours:     "our title\n\none\ntwo\nthree\n\nfooter\n",
+			theirs:   "title\n\none\ntwo\nthree\nfour\n\nfooter\n",
+			expected: "our title\n\none\ntwo\nthree\nfour\n\nfooter\n",
+		},
+		{
+			name:     "identical changes on both sides",
+			ours:     "title\n\none\nTWO\nthree\n\nfooter\n",
+			theirs:   "title\n\none\nTWO\nthree\n\nfooter\n",
+			expected: "title\n\none\nTWO\nthree\n\nfooter\n",
+		},
+		{
+			name:   "conflicting changes get markers",
+			ours:   "title\n\none\nour two\nthree\n\nfooter\n",
+			theirs: "title\n\none\ntheir two\nthree\n\nfooter\n",
+			expected: "title\n\none\n" +
+				"<<<<<<< ours\nour two\n||||||| base\ntwo\n=======\ntheir two\n>>>>>>> theirs\n" +
+				"three\n\nfooter\n",
+			expectedConflict: true,
+		},
+		{
+			name:     "deletion on one side",
+			ours:     "title\n\none\nthree\n\nfooter\n",
+			theirs:   "title\n\none\ntwo\nthree\n\nnew footer\n",
+			expected: "title\n\none\nthree\n\nnew footer\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflicted := Merge3(base, tt.ours, tt.theirs, labels)
+			if conflicted != tt.expectedConflict {
+				t.Errorf("Expected conflict=%v, got %v", tt.expectedConflict, conflicted)
+			}
+			if got != tt.expected {
+				t.Errorf("Unexpected merge result:\nexpected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
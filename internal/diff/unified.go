@@ -0,0 +1,131 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines shown around each change
+const DefaultContext = 3
+
+// Unified returns a unified diff turning oldText into newText, labelled with
+// the given file names. It returns an empty string when the texts are equal.
+func Unified(oldName, newName, oldText, newText string, context int) string {
+	if oldText == newText {
+		return ""
+	}
+
+	edits := Compute(Lines(oldText), Lines(newText))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n", oldName)
+	fmt.Fprintf(&b, "+++ %s\n", newName)
+	for _, h := range hunks(edits, context) {
+		h.write(&b)
+	}
+	return b.String()
+}
+
+// hunk is a group of nearby changes plus their surrounding context
+type hunk struct {
+	oldStart, oldLines int
+	newStart, newLines int
+	edits              []Edit
+}
+
+// hunks groups an edit script into hunks, merging changes separated by no
+// more than 2*context unchanged lines
+func hunks(edits []Edit, context int) []hunk {
+	var result []hunk
+	oldLine, newLine := 1, 1
+	i := 0
+	for i < len(edits) {
+		// Skip to the next change
+		if edits[i].Op == Equal {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// Back up to include leading context
+		start := i
+		for start > 0 && i-start < context && edits[start-1].Op == Equal {
+			start--
+		}
+		h := hunk{oldStart: oldLine - (i - start), newStart: newLine - (i - start)}
+
+		// Extend until a run of unchanged lines is too long to bridge
+		end := i
+		for end < len(edits) {
+			if edits[end].Op != Equal {
+				end++
+				continue
+			}
+			run := end
+			for run < len(edits) && edits[run].Op == Equal {
+				run++
+			}
+			if run == len(edits) || run-end > 2*context {
+				end += min(context, run-end)
+				break
+			}
+			end = run
+		}
+
+		h.edits = edits[start:end]
+		for _, e := range h.edits {
+			if e.Op != Insert {
+				h.oldLines++
+			}
+			if e.Op != Delete {
+				h.newLines++
+			}
+		}
+		result = append(result, h)
+
+		for _, e := range edits[i:end] {
+			if e.Op != Insert {
+				oldLine++
+			}
+			if e.Op != Delete {
+				newLine++
+			}
+		}
+		i = end
+	}
+	return result
+}
+
+func (h hunk) write(b *strings.Builder) {
+	oldStart, newStart := h.oldStart, h.newStart
+	// An empty range is reported as starting on the line before it
+	if h.oldLines == 0 {
+		oldStart--
+	}
+	if h.newLines == 0 {
+		newStart--
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(oldStart, h.oldLines), hunkRange(newStart, h.newLines))
+	for _, e := range h.edits {
+		prefix := " "
+		switch e.Op {
+		case Delete:
+			prefix = "-"
+		case Insert:
+			prefix = "+"
+		}
+		b.WriteString(prefix)
+		b.WriteString(e.Line)
+		if !strings.HasSuffix(e.Line, "\n") {
+			b.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
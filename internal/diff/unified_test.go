@@ -0,0 +1,82 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldText  string
+		newText  string
+		expected string
+	}{
+		{
+			name:     "identical texts produce no diff",
+			oldText:  "a\nb\n",
+			newText:  "a\nb\n",
+			expected: "",
+		},
+		{
+			name:    "new file",
+			oldText: "",
+			newText: "a\nb\n",
+			expected: "--- old\n+++ new\n" +
+				"@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:    "single line changed",
+			oldText: "a\nb\nc\n",
+			newText: "a\nB\nc\n",
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:    "distant changes produce separate hunks",
+			oldText: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			newText: "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+ten\n",
+		},
+		{
+			name:    "missing trailing newline is marked",
+			oldText: "a\nb",
+			newText: "a\nc",
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Unified("old", "new", tt.oldText, tt.newText, DefaultContext)
+			if got != tt.expected {
+				t.Errorf("Unexpected diff:\nexpected:\n%s\ngot:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestComputeRoundTrip(t *testing.T) {
+	a := Lines("keep\nremove\nkeep too\nchange\n")
+	b := Lines("keep\nkeep too\nchanged\nadded\n")
+
+	var oldSide, newSide strings.Builder
+	for _, e := range Compute(a, b) {
+		if e.Op != Insert {
+			oldSide.WriteString(e.Line)
+		}
+		if e.Op != Delete {
+			newSide.WriteString(e.Line)
+		}
+	}
+
+	if oldSide.String() != strings.Join(a, "") {
+		t.Errorf("Edit script does not reproduce the old text: %q", oldSide.String())
+	}
+	if newSide.String() != strings.Join(b, "") {
+		t.Errorf("Edit script does not reproduce the new text: %q", newSide.String())
+	}
+}
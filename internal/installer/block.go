@@ -0,0 +1,100 @@
+package installer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Managed block markers delimit the region of a shared file that
+// go-agent-kit owns. Everything outside the markers belongs to the user.
+const (
+	BlockVersion = "v1"
+	BlockBegin   = "<!-- go-agent-kit:begin " + BlockVersion + " -->"
+	BlockEnd     = "<!-- go-agent-kit:end -->"
+
+	// blockBeginPrefix matches the begin marker of any block version
+	blockBeginPrefix = "<!-- go-agent-kit:begin"
+)
+
+// findBlock locates the managed block in content, returning the byte offsets
+// of the start of the begin marker line and the end of the end marker line
+func findBlock(content string) (start, end int, found bool, err error) {
+	start = strings.Index(content, blockBeginPrefix)
+	if start < 0 {
+		return 0, 0, false, nil
+	}
+	if strings.Contains(content[start+len(blockBeginPrefix):], blockBeginPrefix) {
+		return 0, 0, false, fmt.Errorf("found more than one go-agent-kit managed block")
+	}
+
+	rel := strings.Index(content[start:], BlockEnd)
+	if rel < 0 {
+		return 0, 0, false, fmt.Errorf("go-agent-kit managed block is missing its end marker %s", BlockEnd)
+	}
+	end = start + rel + len(BlockEnd)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return start, end, true, nil
+}
+
+// formatBlock wraps body in the managed block markers
+func formatBlock(body string) string {
+	if !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	return BlockBegin + "\n" + body + BlockEnd + "\n"
+}
+
+// ExtractBlock returns the body of the managed block in content
+func ExtractBlock(content string) (body string, found bool, err error) {
+	start, end, found, err := findBlock(content)
+	if !found || err != nil {
+		return "", found, err
+	}
+
+	block := content[start:end]
+	// Drop the begin marker line and the end marker
+	body = block[strings.Index(block, "\n")+1:]
+	body = body[:strings.LastIndex(body, BlockEnd)]
+	return body, true, nil
+}
+
+// UpsertBlock replaces the managed block in content with body, or appends a
+// new block after the existing content when there is none. Content outside
+// the block is preserved byte for byte.
+func UpsertBlock(content, body string) (string, error) {
+	start, end, found, err := findBlock(content)
+	if err != nil {
+		return "", err
+	}
+
+	block := formatBlock(body)
+	if found {
+		return content[:start] + block + content[end:], nil
+	}
+	if strings.TrimSpace(content) == "" {
+		return block, nil
+	}
+	return strings.TrimRight(content, "\n") + "\n\n" + block, nil
+}
+
+// RemoveBlock deletes the managed block from content along with the blank
+// line separating it from the user's content
+func RemoveBlock(content string) (string, bool, error) {
+	start, end, found, err := findBlock(content)
+	if !found || err != nil {
+		return content, false, err
+	}
+
+	before := strings.TrimRight(content[:start], "\n")
+	after := strings.TrimLeft(content[end:], "\n")
+	switch {
+	case before == "":
+		return after, true, nil
+	case after == "":
+		return before + "\n", true, nil
+	default:
+		return before + "\n\n" + after, true, nil
+	}
+}
@@ -0,0 +1,144 @@
+package installer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUpsertBlock(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		body          string
+		expected      string
+		expectedError bool
+	}{
+		{
+			name:     "empty file gets just the block",
+			content:  "",
+			body:     "kit\n",
+			expected: BlockBegin + "\nkit\n" + BlockEnd + "\n",
+		},
+		{
+			name:     "block is appended after user content",
+			content:  "# Ours\n",
+			body:     "kit",
+			expected: "# Ours\n\n" + BlockBegin + "\nkit\n" + BlockEnd + "\n",
+		},
+		{
+			name:     "existing block is replaced in place",
+			content:  "above\n" + BlockBegin + "\nold\n" + BlockEnd + "\nbelow\n",
+			body:     "new\n",
+			expected: "above\n" + BlockBegin + "\nnew\n" + BlockEnd + "\nbelow\n",
+		},
+		{
+			name:     "blocks from other versions are replaced",
+			content:  "<!-- go-agent-kit:begin v0 -->\nold\n" + BlockEnd + "\n",
+			body:     "new\n",
+			expected: BlockBegin + "\nnew\n" + BlockEnd + "\n",
+		},
+		{
+			name:          "unterminated block is an error",
+			content:       BlockBegin + "\nold\n",
+			body:          "new\n",
+			expectedError: true,
+		},
+		{
+			name:          "duplicate blocks are an error",
+			content:       BlockBegin + "\n" + BlockEnd + "\n" + BlockBegin + "\n" + BlockEnd + "\n",
+			body:          "new\n",
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UpsertBlock(tt.content, tt.body)
+			if tt.expectedError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestExtractBlock(t *testing.T) {
+	body, found, err := ExtractBlock("above\n" + BlockBegin + "\nkit\nlines\n" + BlockEnd + "\nbelow\n")
+	if err != nil || !found {
+		t.Fatalf("Expected block to be found, got %v, %v", found, err)
+	}
+	if body != "kit\nlines\n" {
+		t.Errorf("Unexpected block body %q", body)
+	}
+
+	if _, found, _ := ExtractBlock("no block here\n"); found {
+		t.Error("Did not expect a block")
+	}
+}
+
+func TestRemoveBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		removed  bool
+	}{
+		{
+			name:     "block between user content",
+			content:  "above\n\n" + BlockBegin + "\nkit\n" + BlockEnd + "\n\nbelow\n",
+			expected: "above\n\nbelow\n",
+			removed:  true,
+		},
+		{
+			name:     "block at the end",
+			content:  "above\n\n" + BlockBegin + "\nkit\n" + BlockEnd + "\n",
+			expected: "above\n",
+			removed:  true,
+		},
+		{
+			name:     "block only",
+			content:  BlockBegin + "\nkit\n" + BlockEnd + "\n",
+			expected: "",
+			removed:  true,
+		},
+		{
+			name:     "no block",
+			content:  "just ours\n",
+			expected: "just ours\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, removed, err := RemoveBlock(tt.content)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if removed != tt.removed {
+				t.Errorf("Expected removed=%v, got %v", tt.removed, removed)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	// Round trip: inserting and removing a block restores the user's file
+	original := "# Ours\n\nSome rules.\n"
+	withBlock, _ := UpsertBlock(original, "kit\n")
+	restored, _, _ := RemoveBlock(withBlock)
+	if restored != original {
+		t.Errorf("Expected round trip to restore %q, got %q", original, restored)
+	}
+	if !strings.Contains(withBlock, BlockBegin) {
+		t.Error("Expected block to be inserted")
+	}
+}
@@ -0,0 +1,124 @@
+package installer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Policy decides what happens to an existing file whose content differs from
+// what the installer wants to write
+type Policy string
+
+const (
+	// PolicySkip leaves the existing file untouched
+	PolicySkip Policy = "skip"
+	// PolicyOverwrite replaces the existing file
+	PolicyOverwrite Policy = "overwrite"
+	// PolicyBackup copies the existing file to a timestamped backup, then replaces it
+	PolicyBackup Policy = "backup"
+	// PolicyPrompt asks the user which policy to apply, file by file
+	PolicyPrompt Policy = "prompt"
+	// PolicyMerge keeps the existing content and writes the generated content
+	// into its managed block. Only files shared through a managed block can
+	// be merged: a whole generated file has nowhere to put both.
+	PolicyMerge Policy = "merge"
+)
+
+// Policies lists every supported policy in the order they are documented
+var Policies = []Policy{PolicySkip, PolicyOverwrite, PolicyBackup, PolicyPrompt, PolicyMerge}
+
+// ParsePolicy converts a flag value into a Policy
+func ParsePolicy(s string) (Policy, error) {
+	for _, p := range Policies {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	names := make([]string, len(Policies))
+	for i, p := range Policies {
+		names[i] = string(p)
+	}
+	return "", fmt.Errorf("unknown conflict policy %q (expected one of %s)", s, strings.Join(names, ", "))
+}
+
+// AskFunc is called for every conflicting file under PolicyPrompt and returns
+// the policy to apply to that file. It must not return PolicyPrompt.
+type AskFunc func(op Operation) (Policy, error)
+
+// Resolve applies a conflict policy to every operation that would overwrite
+// an existing file, returning the operations to hand to Apply. now is used to
+// timestamp backup files.
+func Resolve(ops []Operation, policy Policy, ask AskFunc, now time.Time) ([]Operation, error) {
+	resolved := make([]Operation, len(ops))
+	for i, op := range ops {
+		if op.Action != ActionOverwrite {
+			resolved[i] = op
+			continue
+		}
+
+		p := policy
+		if p == PolicyPrompt {
+			if ask == nil {
+				return nil, fmt.Errorf("conflict policy %q requires interactive input", PolicyPrompt)
+			}
+			answer, err := ask(op)
+			if err != nil {
+				return nil, err
+			}
+			p = answer
+		}
+
+		switch p {
+		case PolicySkip:
+			op.Action = ActionSkip
+		case PolicyOverwrite:
+			// Nothing to change: the operation already overwrites
+		case PolicyBackup:
+			op.Action = ActionBackup
+			op.BackupPath = backupPath(op.Path, now)
+		case PolicyMerge:
+			if !op.Block {
+				return nil, fmt.Errorf("cannot merge %s: the generated file has no managed block to merge into; use the backup or overwrite policy to replace it, or skip to keep it", op.Path)
+			}
+			merged, generated, err := mergeIntoBlock(op.Current, op.Desired)
+			if err != nil {
+				return nil, fmt.Errorf("failed to merge %s: %w", op.Path, err)
+			}
+			op.Desired, op.Generated = merged, generated
+			op.Action = ActionMerge
+			if bytes.Equal(op.Desired, op.Current) {
+				op.Action = ActionUnchanged
+			}
+		default:
+			return nil, fmt.Errorf("cannot resolve conflict for %s with policy %q", op.Path, p)
+		}
+		resolved[i] = op
+	}
+	return resolved, nil
+}
+
+// backupPath returns the timestamped location an existing file is copied to
+// before being replaced
+func backupPath(path string, now time.Time) string {
+	return fmt.Sprintf("%s.%s.bak", path, now.Format("20060102-150405"))
+}
+
+// mergeIntoBlock keeps the existing content and writes the managed block of
+// desired into it, replacing the block the existing content holds or adding
+// one below it. It also returns the block body, for the manifest.
+func mergeIntoBlock(current, desired []byte) ([]byte, []byte, error) {
+	body, found, err := ExtractBlock(string(desired))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !found {
+		return nil, nil, fmt.Errorf("generated content has no managed block")
+	}
+	merged, err := UpsertBlock(string(current), body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []byte(merged), []byte(body), nil
+}
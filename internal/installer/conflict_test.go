@@ -0,0 +1,151 @@
+package installer
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParsePolicy(t *testing.T) {
+	for _, p := range Policies {
+		got, err := ParsePolicy(string(p))
+		if err != nil || got != p {
+			t.Errorf("Expected %q to parse, got %q, %v", p, got, err)
+		}
+	}
+
+	if _, err := ParsePolicy("clobber"); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	conflict := Operation{Path: "a.md", Action: ActionOverwrite, Current: []byte("mine\n"), Desired: []byte("kit\n")}
+	created := Operation{Path: "b.md", Action: ActionCreate, Desired: []byte("kit\n")}
+	block := BlockBegin + "\nkit\n" + BlockEnd + "\n"
+
+	tests := []struct {
+		name            string
+		policy          Policy
+		ask             AskFunc
+		current         []byte
+		block           bool
+		expectedAction  Action
+		expectedDesired string
+		expectedBackup  string
+		expectedError   bool
+	}{
+		{
+			name:            "skip",
+			policy:          PolicySkip,
+			expectedAction:  ActionSkip,
+			expectedDesired: "kit\n",
+		},
+		{
+			name:            "overwrite",
+			policy:          PolicyOverwrite,
+			expectedAction:  ActionOverwrite,
+			expectedDesired: "kit\n",
+		},
+		{
+			name:            "backup",
+			policy:          PolicyBackup,
+			expectedAction:  ActionBackup,
+			expectedDesired: "kit\n",
+			expectedBackup:  "a.md.20240102-030405.bak",
+		},
+		{
+			name:            "merge adds the managed block to the existing content",
+			policy:          PolicyMerge,
+			block:           true,
+			expectedAction:  ActionMerge,
+			expectedDesired: "mine\n\n" + block,
+		},
+		{
+			name:            "merge replaces the managed block of the existing content",
+			policy:          PolicyMerge,
+			current:         []byte("mine\n\n" + BlockBegin + "\nold\n" + BlockEnd + "\nmore\n"),
+			block:           true,
+			expectedAction:  ActionMerge,
+			expectedDesired: "mine\n\n" + block + "more\n",
+		},
+		{
+			name:            "merge is unchanged when the block is already present",
+			policy:          PolicyMerge,
+			current:         []byte("mine\n\n" + block),
+			block:           true,
+			expectedAction:  ActionUnchanged,
+			expectedDesired: "mine\n\n" + block,
+		},
+		{
+			name:          "merge refuses a whole generated file",
+			policy:        PolicyMerge,
+			expectedError: true,
+		},
+		{
+			name:   "prompt uses the answer",
+			policy: PolicyPrompt,
+			ask: func(op Operation) (Policy, error) {
+				return PolicySkip, nil
+			},
+			expectedAction:  ActionSkip,
+			expectedDesired: "kit\n",
+		},
+		{
+			name:   "prompt propagates errors",
+			policy: PolicyPrompt,
+			ask: func(op Operation) (Policy, error) {
+				return "", errors.New("interrupted")
+			},
+			expectedError: true,
+		},
+		{
+			name:          "prompt without ask func fails",
+			policy:        PolicyPrompt,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := conflict
+			if tt.current != nil {
+				op.Current = tt.current
+			}
+			if tt.block {
+				op.Block = true
+				op.Desired = []byte(block)
+			}
+
+			ops, err := Resolve([]Operation{op, created}, tt.policy, tt.ask, now)
+			if tt.expectedError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if ops[0].Action != tt.expectedAction {
+				t.Errorf("Expected action %s, got %s", tt.expectedAction, ops[0].Action)
+			}
+			if string(ops[0].Desired) != tt.expectedDesired {
+				t.Errorf("Expected desired content %q, got %q", tt.expectedDesired, ops[0].Desired)
+			}
+			if tt.block && string(ops[0].Generated) != "kit\n" {
+				t.Errorf("Expected the block body to be recorded as generated, got %q", ops[0].Generated)
+			}
+			if ops[0].BackupPath != tt.expectedBackup {
+				t.Errorf("Expected backup path %q, got %q", tt.expectedBackup, ops[0].BackupPath)
+			}
+
+			// Non-conflicting operations are never affected by the policy
+			if ops[1].Action != ActionCreate {
+				t.Errorf("Expected create operation to be left alone, got %s", ops[1].Action)
+			}
+		})
+	}
+}
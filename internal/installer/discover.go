@@ -0,0 +1,46 @@
+package installer
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// skippedDirs are never searched for repositories: they hold dependencies or
+// build output rather than projects of their own
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	".venv":        true,
+	"target":       true,
+}
+
+// FindRepositories returns every git repository at or beneath root, i.e.
+// every directory containing a .git directory (or a .git file, as used by
+// worktrees and submodules), sorted by path
+func FindRepositories(root string) ([]string, error) {
+	var repos []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && skippedDirs[d.Name()] || d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if _, err := os.Lstat(filepath.Join(path, ".git")); err == nil {
+			repos = append(repos, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s for repositories: %w", root, err)
+	}
+
+	sort.Strings(repos)
+	return repos, nil
+}
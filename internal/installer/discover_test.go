@@ -0,0 +1,51 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindRepositories(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "discover-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	for _, dir := range []string{
+		".git",
+		"services/api/.git",
+		"services/api/vendor/lib/.git",
+		"services/worker/.git/objects",
+		"frontend/node_modules/pkg/.git",
+		"docs",
+	} {
+		if err := os.MkdirAll(filepath.Join(tempDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Submodules and worktrees use a .git file instead of a directory
+	if err := os.MkdirAll(filepath.Join(tempDir, "libs/shared"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "libs/shared/.git"), []byte("gitdir: ../../.git/modules/shared\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repos, err := FindRepositories(tempDir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		tempDir,
+		filepath.Join(tempDir, "libs/shared"),
+		filepath.Join(tempDir, "services/api"),
+		filepath.Join(tempDir, "services/worker"),
+	}
+	if !reflect.DeepEqual(repos, expected) {
+		t.Errorf("Expected %v, got %v", expected, repos)
+	}
+}
@@ -0,0 +1,214 @@
+// Package installer plans and applies the file changes made by go-agent-kit
+// commands, so that every change can be previewed before it touches disk.
+package installer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/johnayoung/go-agent-kit/internal/diff"
+)
+
+// Action describes what the installer will do with a single file
+type Action int
+
+const (
+	// ActionCreate writes a file that does not exist yet
+	ActionCreate Action = iota
+	// ActionOverwrite replaces a file whose content differs
+	ActionOverwrite
+	// ActionUpdate changes only the managed block of a shared file
+	ActionUpdate
+	// ActionUnchanged leaves a file that already has the desired content
+	ActionUnchanged
+	// ActionSkip leaves a conflicting file untouched
+	ActionSkip
+	// ActionBackup copies a conflicting file to BackupPath before replacing it
+	ActionBackup
+	// ActionMerge replaces a conflicting file with a merge of both versions
+	ActionMerge
+	// ActionRemove deletes a file the kit generated earlier and no longer does
+	ActionRemove
+)
+
+// String returns the lower-case name of the action
+func (a Action) String() string {
+	switch a {
+	case ActionCreate:
+		return "create"
+	case ActionOverwrite:
+		return "overwrite"
+	case ActionUpdate:
+		return "update"
+	case ActionUnchanged:
+		return "unchanged"
+	case ActionSkip:
+		return "skip"
+	case ActionBackup:
+		return "backup"
+	case ActionMerge:
+		return "merge"
+	case ActionRemove:
+		return "remove"
+	default:
+		return fmt.Sprintf("action(%d)", int(a))
+	}
+}
+
+// File is a file that should exist with the given content
+type File struct {
+	Path     string // slash-separated, relative to the install root
+	Content  []byte
+	Template string // template the content was generated from
+
+	// Block is set when Content was produced by UpsertBlock on the file's
+	// current content, so replacing the file never loses user edits
+	Block bool
+	// Edited is set for a Block file whose current content cannot be kept,
+	// such as a file written whole by an older version and edited since:
+	// replacing it is a conflict like for any other file
+	Edited bool
+}
+
+// Operation is a planned change to a single file
+type Operation struct {
+	Path     string // slash-separated, relative to the install root
+	Action   Action
+	Current  []byte // content on disk, nil when the file does not exist
+	Desired  []byte // content the file should have
+	Template string
+	Block    bool
+
+	BackupPath string // where ActionBackup copies the existing file
+
+	// Generated is the kit-owned content as generated, when Desired also
+	// carries the user's edits (set by upgrade merges)
+	Generated []byte
+	// Conflict is set when Desired contains unresolved merge conflict markers
+	Conflict bool
+	// Orphaned is set for a file the kit no longer generates: its manifest
+	// entry is dropped, whether the file is removed or kept for its edits
+	Orphaned bool
+}
+
+// Plan compares each file beneath root with what is on disk and returns the
+// operations needed to bring the disk in line, without writing anything.
+// Files recorded in the manifest that have not been edited since they were
+// installed are updated freely; any other difference is a conflict
+// (ActionOverwrite) left to Resolve.
+func Plan(root string, files []File, manifest *Manifest) ([]Operation, error) {
+	ops := make([]Operation, 0, len(files))
+	for _, f := range files {
+		op := Operation{Path: f.Path, Desired: f.Content, Template: f.Template, Block: f.Block}
+
+		current, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(f.Path)))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			op.Action = ActionCreate
+		case err != nil:
+			return nil, fmt.Errorf("failed to read %s: %w", f.Path, err)
+		case bytes.Equal(current, f.Content):
+			op.Current = current
+			op.Action = ActionUnchanged
+		case f.Block && !f.Edited, manifest.Unmodified(f.Path, current):
+			op.Current = current
+			op.Action = ActionUpdate
+		default:
+			op.Current = current
+			op.Action = ActionOverwrite
+		}
+
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// PlanPrune returns the operations retiring the files generated earlier from
+// template that files no longer includes, such as the instructions of a
+// sub-project since removed. Unedited files are removed (ActionRemove);
+// edited ones are kept (ActionSkip). Either way their entries are dropped.
+func PlanPrune(root string, files []File, m *Manifest, template string) ([]Operation, error) {
+	generated := make(map[string]bool, len(files))
+	for _, f := range files {
+		generated[f.Path] = true
+	}
+
+	var ops []Operation
+	for _, e := range m.Files {
+		if e.Template != template || generated[e.Path] {
+			continue
+		}
+		if err := checkPath(e.Path); err != nil {
+			return nil, err
+		}
+		op := Operation{Path: e.Path, Action: ActionRemove, Template: e.Template, Orphaned: true}
+
+		current, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(e.Path)))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			// Already gone: only the entry is left to drop
+		case err != nil:
+			return nil, fmt.Errorf("failed to read %s: %w", e.Path, err)
+		case m.Unmodified(e.Path, current):
+			op.Current = current
+		default:
+			op.Current = current
+			op.Action = ActionSkip
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// Apply performs the planned operations beneath root, creating parent
+// directories as needed
+func Apply(root string, ops []Operation) error {
+	for _, op := range ops {
+		if op.Action == ActionUnchanged || op.Action == ActionSkip {
+			continue
+		}
+		if op.Action == ActionRemove {
+			err := os.Remove(filepath.Join(root, filepath.FromSlash(op.Path)))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("failed to remove %s: %w", op.Path, err)
+			}
+			pruneEmptyDirs(root, path.Dir(op.Path))
+			continue
+		}
+		path := filepath.Join(root, filepath.FromSlash(op.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", op.Path, err)
+		}
+		if op.Action == ActionBackup {
+			backup := filepath.Join(root, filepath.FromSlash(op.BackupPath))
+			if err := os.WriteFile(backup, op.Current, 0644); err != nil {
+				return fmt.Errorf("failed to back up %s: %w", op.Path, err)
+			}
+		}
+		if err := os.WriteFile(path, op.Desired, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", op.Path, err)
+		}
+	}
+	return nil
+}
+
+// Diff returns a unified diff between the file on disk and its desired
+// content, or an empty string when the operation changes nothing
+func (op Operation) Diff() string {
+	if op.Action == ActionUnchanged || op.Action == ActionSkip {
+		return ""
+	}
+	oldName, newName := "a/"+op.Path, "b/"+op.Path
+	switch op.Action {
+	case ActionCreate:
+		oldName = "/dev/null"
+	case ActionRemove:
+		return diff.Unified(oldName, "/dev/null", string(op.Current), "", diff.DefaultContext)
+	}
+	return diff.Unified(oldName, newName, string(op.Current), string(op.Desired), diff.DefaultContext)
+}
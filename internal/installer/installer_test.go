@@ -0,0 +1,189 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "installer-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := os.WriteFile(filepath.Join(tempDir, "same.md"), []byte("same\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "changed.md"), []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "installed.md"), []byte("kit v1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "legacy.md"), []byte("old kit\nteam notes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// installed.md is unedited kit output, so it may be updated without conflict
+	manifest := &Manifest{}
+	manifest.Set(ManifestEntry{Path: "installed.md", Kind: KindFile, SHA256: HashContent([]byte("kit v1\n"))})
+
+	ops, err := Plan(tempDir, []File{
+		{Path: "same.md", Content: []byte("same\n")},
+		{Path: "changed.md", Content: []byte("new\n")},
+		{Path: "nested/new.md", Content: []byte("created\n")},
+		{Path: "installed.md", Content: []byte("kit v2\n")},
+		// A block file whose old content cannot be kept conflicts
+		{Path: "legacy.md", Content: []byte(BlockBegin + "\nkit\n" + BlockEnd + "\n"), Block: true, Edited: true},
+	}, manifest)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []Action{ActionUnchanged, ActionOverwrite, ActionCreate, ActionUpdate, ActionOverwrite}
+	for i, op := range ops {
+		if op.Action != expected[i] {
+			t.Errorf("Expected %s to be planned as %s, got %s", op.Path, expected[i], op.Action)
+		}
+	}
+
+	// Planning must not touch the disk
+	if _, err := os.Stat(filepath.Join(tempDir, "nested", "new.md")); !os.IsNotExist(err) {
+		t.Error("Plan should not create files")
+	}
+
+	if err := Apply(tempDir, ops); err != nil {
+		t.Fatalf("Unexpected error applying plan: %v", err)
+	}
+	for _, f := range []struct{ path, content string }{
+		{"same.md", "same\n"},
+		{"changed.md", "new\n"},
+		{"nested/new.md", "created\n"},
+		{"installed.md", "kit v2\n"},
+		{"legacy.md", BlockBegin + "\nkit\n" + BlockEnd + "\n"},
+	} {
+		content, err := os.ReadFile(filepath.Join(tempDir, filepath.FromSlash(f.path)))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", f.path, err)
+		}
+		if string(content) != f.content {
+			t.Errorf("Expected %s to contain %q, got %q", f.path, f.content, content)
+		}
+	}
+}
+
+func TestPlanPrune(t *testing.T) {
+	tempDir := t.TempDir()
+	write := func(path, content string) {
+		full := filepath.Join(tempDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".github/scoped/kept.md", "kit\n")
+	write(".github/scoped/stale.md", "kit\n")
+	write(".github/scoped/edited.md", "kit, edited\n")
+	write(".github/prompts/other.md", "kit\n")
+
+	m := &Manifest{}
+	hash := HashContent([]byte("kit\n"))
+	for _, path := range []string{".github/scoped/kept.md", ".github/scoped/stale.md", ".github/scoped/edited.md", ".github/scoped/gone.md"} {
+		m.Set(ManifestEntry{Path: path, Kind: KindFile, Template: "scoped", SHA256: hash})
+	}
+	m.Set(ManifestEntry{Path: ".github/prompts/other.md", Kind: KindFile, Template: "other", SHA256: hash})
+
+	ops, err := PlanPrune(tempDir, []File{{Path: ".github/scoped/kept.md", Content: []byte("kit\n")}}, m, "scoped")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]Action{
+		".github/scoped/stale.md":  ActionRemove,
+		".github/scoped/edited.md": ActionSkip,
+		".github/scoped/gone.md":   ActionRemove,
+	}
+	if len(ops) != len(expected) {
+		t.Fatalf("Expected %d operations, got %+v", len(expected), ops)
+	}
+	for _, op := range ops {
+		if op.Action != expected[op.Path] || !op.Orphaned {
+			t.Errorf("Unexpected operation for %s: %s (orphaned %v)", op.Path, op.Action, op.Orphaned)
+		}
+	}
+
+	if err := Apply(tempDir, ops); err != nil {
+		t.Fatalf("Unexpected error applying: %v", err)
+	}
+	m.Record(ops, "v2")
+	if _, err := os.Stat(filepath.Join(tempDir, ".github", "scoped", "stale.md")); !os.IsNotExist(err) {
+		t.Error("Expected the stale file to be removed")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".github", "scoped", "edited.md")); err != nil {
+		t.Errorf("Expected the edited file to be kept: %v", err)
+	}
+	var paths []string
+	for _, e := range m.Files {
+		paths = append(paths, e.Path)
+	}
+	if strings.Join(paths, ",") != ".github/scoped/kept.md,.github/prompts/other.md" {
+		t.Errorf("Expected only the regenerated and other entries to remain, got %v", paths)
+	}
+}
+
+func TestOperationDiff(t *testing.T) {
+	tests := []struct {
+		name           string
+		op             Operation
+		expectedInDiff []string
+	}{
+		{
+			name: "create diffs against /dev/null",
+			op:   Operation{Path: "dir/new.md", Action: ActionCreate, Desired: []byte("hello\n")},
+			expectedInDiff: []string{
+				"--- /dev/null",
+				"+++ b/dir/new.md",
+				"+hello",
+			},
+		},
+		{
+			name: "overwrite shows removed and added lines",
+			op:   Operation{Path: "old.md", Action: ActionOverwrite, Current: []byte("before\n"), Desired: []byte("after\n")},
+			expectedInDiff: []string{
+				"--- a/old.md",
+				"+++ b/old.md",
+				"-before",
+				"+after",
+			},
+		},
+		{
+			name: "remove diffs against /dev/null",
+			op:   Operation{Path: "gone.md", Action: ActionRemove, Current: []byte("bye\n")},
+			expectedInDiff: []string{
+				"--- a/gone.md",
+				"+++ /dev/null",
+				"-bye",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.op.Diff()
+			for _, expected := range tt.expectedInDiff {
+				if !strings.Contains(d, expected) {
+					t.Errorf("Expected to find '%s' in diff:\n%s", expected, d)
+				}
+			}
+		})
+	}
+
+	unchanged := Operation{Path: "same.md", Action: ActionUnchanged, Current: []byte("x\n"), Desired: []byte("x\n")}
+	if d := unchanged.Diff(); d != "" {
+		t.Errorf("Expected no diff for unchanged file, got:\n%s", d)
+	}
+}
@@ -0,0 +1,197 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ManifestPath is where the install manifest lives, relative to the install root
+const ManifestPath = ".github/.go-agent-kit.lock"
+
+// Entry kinds recorded in the manifest
+const (
+	// KindFile entries are whole files owned by go-agent-kit
+	KindFile = "file"
+	// KindBlock entries are managed blocks inside a file shared with the user
+	KindBlock = "block"
+)
+
+// Manifest records every file go-agent-kit wrote, so later commands can tell
+// kit-generated content apart from the user's edits
+type Manifest struct {
+	Version string          `json:"version"`
+	Files   []ManifestEntry `json:"files"`
+}
+
+// ManifestEntry describes a single generated file or managed block
+type ManifestEntry struct {
+	Path     string `json:"path"`
+	Kind     string `json:"kind"`
+	Template string `json:"template"`
+	Version  string `json:"version"`
+	SHA256   string `json:"sha256"`
+
+	// Content is the generated content as installed, kept as the common
+	// ancestor for three-way merges on upgrade
+	Content string `json:"content,omitempty"`
+}
+
+// LoadManifest reads the manifest beneath root. A missing manifest is not an
+// error: it returns an empty manifest, as for a repository installed by an
+// older version or not installed at all.
+func LoadManifest(root string) (*Manifest, error) {
+	path := filepath.Join(root, filepath.FromSlash(ManifestPath))
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Manifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", ManifestPath, err)
+	}
+	return &m, nil
+}
+
+// Save writes the manifest beneath root
+func (m *Manifest) Save(root string) error {
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	path := filepath.Join(root, filepath.FromSlash(ManifestPath))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for manifest: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// checkPath rejects manifest paths that would reach outside the .github
+// directory of the install root; the manifest is a file in the repository,
+// so anyone able to commit can edit it
+func checkPath(p string) error {
+	if !filepath.IsLocal(filepath.FromSlash(p)) || path.Clean(p) != p || !strings.HasPrefix(p, ".github/") {
+		return fmt.Errorf("manifest entry %q is not a path inside .github/", p)
+	}
+	return nil
+}
+
+// Entry returns the manifest entry for path
+func (m *Manifest) Entry(path string) (ManifestEntry, bool) {
+	if m == nil {
+		return ManifestEntry{}, false
+	}
+	for _, e := range m.Files {
+		if e.Path == path {
+			return e, true
+		}
+	}
+	return ManifestEntry{}, false
+}
+
+// Set adds or replaces the entry for e.Path
+func (m *Manifest) Set(e ManifestEntry) {
+	for i := range m.Files {
+		if m.Files[i].Path == e.Path {
+			m.Files[i] = e
+			return
+		}
+	}
+	m.Files = append(m.Files, e)
+}
+
+// Remove deletes the entry for path
+func (m *Manifest) Remove(path string) {
+	for i := range m.Files {
+		if m.Files[i].Path == path {
+			m.Files = append(m.Files[:i], m.Files[i+1:]...)
+			return
+		}
+	}
+}
+
+// Unmodified reports whether content is exactly what the manifest recorded
+// for path, i.e. the user has not edited the kit-owned content since install
+func (m *Manifest) Unmodified(path string, content []byte) bool {
+	e, ok := m.Entry(path)
+	if !ok {
+		return false
+	}
+	owned, ok := OwnedContent(e.Kind, content)
+	return ok && HashContent(owned) == e.SHA256
+}
+
+// OwnedContent returns the part of a file go-agent-kit owns: the whole file
+// for KindFile, or the managed block body for KindBlock
+func OwnedContent(kind string, content []byte) ([]byte, bool) {
+	if kind != KindBlock {
+		return content, true
+	}
+	body, found, err := ExtractBlock(string(content))
+	if !found || err != nil {
+		return nil, false
+	}
+	return []byte(body), true
+}
+
+// HashContent returns the hex-encoded SHA-256 of content
+func HashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Record updates the manifest with the outcome of applied operations. The
+// entry holds the generated content, not any user edits merged into it.
+// Skipped files, and files merged without knowing the generated content,
+// keep their previous entries, if any; orphaned files lose theirs.
+func (m *Manifest) Record(ops []Operation, version string) {
+	m.Version = version
+	for _, op := range ops {
+		if op.Orphaned {
+			m.Remove(op.Path)
+			continue
+		}
+		if op.Action == ActionSkip || (op.Action == ActionMerge && op.Generated == nil) {
+			continue
+		}
+
+		kind := KindFile
+		if op.Block {
+			kind = KindBlock
+		}
+		owned := op.Generated
+		if owned == nil {
+			var ok bool
+			if owned, ok = OwnedContent(kind, op.Desired); !ok {
+				continue
+			}
+		}
+
+		m.Set(ManifestEntry{
+			Path:     op.Path,
+			Kind:     kind,
+			Template: op.Template,
+			Version:  version,
+			SHA256:   HashContent(owned),
+			Content:  string(owned),
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package installer
+
+import (
+	"os"
+	"testing"
+)
+
+func TestManifestSaveAndLoad(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "manifest-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// A missing manifest loads as empty
+	m, err := LoadManifest(tempDir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(m.Files) != 0 {
+		t.Errorf("Expected empty manifest, got %d files", len(m.Files))
+	}
+
+	m.Record([]Operation{
+		{Path: "b.md", Action: ActionCreate, Desired: []byte("b\n"), Template: "b"},
+		{Path: "a.md", Action: ActionUpdate, Desired: []byte("mine\n" + BlockBegin + "\nkit\n" + BlockEnd + "\n"), Template: "a", Block: true},
+		{Path: "skipped.md", Action: ActionSkip, Desired: []byte("x\n")},
+		{Path: "merged.md", Action: ActionMerge, Desired: []byte("x\n")},
+	}, "1.2.3")
+
+	if err := m.Save(tempDir); err != nil {
+		t.Fatalf("Unexpected error saving: %v", err)
+	}
+
+	loaded, err := LoadManifest(tempDir)
+	if err != nil {
+		t.Fatalf("Unexpected error loading: %v", err)
+	}
+
+	if loaded.Version != "1.2.3" {
+		t.Errorf("Expected version 1.2.3, got %s", loaded.Version)
+	}
+	if len(loaded.Files) != 2 {
+		t.Fatalf("Expected 2 recorded files, got %d", len(loaded.Files))
+	}
+
+	// Entries are sorted by path
+	block := loaded.Files[0]
+	if block.Path != "a.md" || block.Kind != KindBlock || block.Template != "a" || block.Version != "1.2.3" {
+		t.Errorf("Unexpected block entry: %+v", block)
+	}
+	if block.SHA256 != HashContent([]byte("kit\n")) {
+		t.Error("Expected block entry to hash only the managed block body")
+	}
+	if loaded.Files[1].Path != "b.md" || loaded.Files[1].Kind != KindFile {
+		t.Errorf("Unexpected file entry: %+v", loaded.Files[1])
+	}
+}
+
+func TestManifestUnmodified(t *testing.T) {
+	m := &Manifest{}
+	m.Set(ManifestEntry{Path: "file.md", Kind: KindFile, SHA256: HashContent([]byte("kit\n"))})
+	m.Set(ManifestEntry{Path: "shared.md", Kind: KindBlock, SHA256: HashContent([]byte("kit\n"))})
+
+	tests := []struct {
+		name     string
+		path     string
+		content  string
+		expected bool
+	}{
+		{"unedited file", "file.md", "kit\n", true},
+		{"edited file", "file.md", "kit\nmore\n", false},
+		{"unknown file", "other.md", "kit\n", false},
+		{"user content around unedited block", "shared.md", "ours\n" + BlockBegin + "\nkit\n" + BlockEnd + "\n", true},
+		{"edited block", "shared.md", BlockBegin + "\nkit!\n" + BlockEnd + "\n", false},
+		{"block removed", "shared.md", "ours\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Unmodified(tt.path, []byte(tt.content)); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	m.Remove("file.md")
+	if _, ok := m.Entry("file.md"); ok {
+		t.Error("Expected entry to be removed")
+	}
+}
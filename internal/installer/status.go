@@ -0,0 +1,135 @@
+package installer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// State classifies an installed file against the embedded templates
+type State string
+
+const (
+	// StateMissing files (or managed blocks) are not on disk
+	StateMissing State = "missing"
+	// StateUpToDate files match what this version would generate
+	StateUpToDate State = "up-to-date"
+	// StateOutdated files are unedited output of an older version
+	StateOutdated State = "outdated"
+	// StateModified files were edited since go-agent-kit installed them
+	StateModified State = "modified"
+	// StateUnknown files are not recorded as generated by go-agent-kit
+	StateUnknown State = "unknown"
+)
+
+// FileStatus is the state of a single file, with a human readable detail
+type FileStatus struct {
+	Path   string `json:"path"`
+	State  State  `json:"state"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Status classifies every file install would generate beneath root, plus
+// any other files matching the extra glob patterns (such as foreign prompt
+// files), which are reported as StateUnknown
+func Status(root string, files []File, m *Manifest, extra ...string) ([]FileStatus, error) {
+	expected := make(map[string]bool, len(files))
+	statuses := make([]FileStatus, 0, len(files))
+	for _, f := range files {
+		expected[f.Path] = true
+
+		s, err := fileStatus(root, f, m)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, s)
+	}
+
+	var foreign []string
+	for _, pattern := range extra {
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+		}
+		for _, match := range matches {
+			rel, err := filepath.Rel(root, match)
+			if err != nil {
+				return nil, err
+			}
+			rel = filepath.ToSlash(rel)
+			if !expected[rel] {
+				expected[rel] = true
+				foreign = append(foreign, rel)
+			}
+		}
+	}
+	sort.Strings(foreign)
+	for _, p := range foreign {
+		statuses = append(statuses, FileStatus{Path: p, State: StateUnknown, Detail: "not generated by go-agent-kit"})
+	}
+
+	return statuses, nil
+}
+
+func fileStatus(root string, f File, m *Manifest) (FileStatus, error) {
+	s := FileStatus{Path: f.Path}
+
+	kind := KindFile
+	if f.Block {
+		kind = KindBlock
+	}
+
+	current, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(f.Path)))
+	if errors.Is(err, fs.ErrNotExist) {
+		s.State = StateMissing
+		return s, nil
+	}
+	if err != nil {
+		return s, fmt.Errorf("failed to read %s: %w", f.Path, err)
+	}
+
+	owned, found := OwnedContent(kind, current)
+	if !found {
+		s.State = StateMissing
+		s.Detail = "file exists but has no go-agent-kit managed block"
+		return s, nil
+	}
+	desired, _ := OwnedContent(kind, f.Content)
+
+	entry, recorded := m.Entry(f.Path)
+	switch {
+	case bytes.Equal(owned, desired):
+		s.State = StateUpToDate
+	case !recorded:
+		s.State = StateUnknown
+		s.Detail = "differs from the embedded template and is not in the install manifest"
+	case HashContent(owned) != entry.SHA256:
+		s.State = StateModified
+		s.Detail = "edited since go-agent-kit " + entry.Version + " installed it"
+	default:
+		s.State = StateOutdated
+		s.Detail = "generated by go-agent-kit " + entry.Version
+	}
+	return s, nil
+}
+
+// Summary counts statuses by state, e.g. "3 up-to-date, 1 modified"
+func Summary(statuses []FileStatus) string {
+	counts := map[State]int{}
+	for _, s := range statuses {
+		counts[s.State]++
+	}
+
+	var parts []string
+	for _, state := range []State{StateUpToDate, StateOutdated, StateModified, StateMissing, StateUnknown} {
+		if counts[state] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[state], state))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
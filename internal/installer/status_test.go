@@ -0,0 +1,57 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStatusManagedBlock(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "status-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	desired := BlockBegin + "\nkit\n" + BlockEnd + "\n"
+	files := []File{{Path: "shared.md", Content: []byte(desired), Block: true}}
+	m := &Manifest{}
+	m.Set(ManifestEntry{Path: "shared.md", Kind: KindBlock, Version: "0.1.0", SHA256: HashContent([]byte("old kit\n"))})
+
+	tests := []struct {
+		name     string
+		content  string
+		expected State
+	}{
+		{"user content around current block", "ours\n\n" + desired, StateUpToDate},
+		{"unedited block from older version", "ours\n\n" + BlockBegin + "\nold kit\n" + BlockEnd + "\n", StateOutdated},
+		{"edited block", BlockBegin + "\nold kit, edited\n" + BlockEnd + "\n", StateModified},
+		{"block removed", "ours\n", StateMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(filepath.Join(tempDir, "shared.md"), []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			statuses, err := Status(tempDir, files, m)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if statuses[0].State != tt.expected {
+				t.Errorf("Expected %s, got %s (%s)", tt.expected, statuses[0].State, statuses[0].Detail)
+			}
+		})
+	}
+}
+
+func TestSummary(t *testing.T) {
+	got := Summary([]FileStatus{
+		{State: StateModified},
+		{State: StateUpToDate},
+		{State: StateUpToDate},
+	})
+	if got != "2 up-to-date, 1 modified" {
+		t.Errorf("Unexpected summary %q", got)
+	}
+}
@@ -0,0 +1,118 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Removal is a planned uninstall step for a single manifest entry
+type Removal struct {
+	Entry ManifestEntry
+
+	// Missing is set when the file no longer exists
+	Missing bool
+	// Modified is set when the kit-owned content was edited since install
+	Modified bool
+	// Delete removes the whole file; otherwise Content is written back
+	Delete  bool
+	Content []byte
+}
+
+// PlanUninstall works out how to remove every manifest entry beneath root.
+// Whole files are deleted; managed blocks are stripped from their file, which
+// is deleted only when nothing but the block remains. Entries without a
+// recorded hash count as modified, and paths outside .github/ are refused.
+func PlanUninstall(root string, m *Manifest) ([]Removal, error) {
+	removals := make([]Removal, 0, len(m.Files))
+	for _, e := range m.Files {
+		if err := checkPath(e.Path); err != nil {
+			return nil, err
+		}
+		r := Removal{Entry: e}
+
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(e.Path)))
+		if errors.Is(err, fs.ErrNotExist) {
+			r.Missing = true
+			removals = append(removals, r)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", e.Path, err)
+		}
+
+		r.Modified = e.SHA256 == "" || !m.Unmodified(e.Path, content)
+
+		if e.Kind != KindBlock {
+			r.Delete = true
+			removals = append(removals, r)
+			continue
+		}
+
+		stripped, found, err := RemoveBlock(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove managed block from %s: %w", e.Path, err)
+		}
+		if !found {
+			// The user removed the block themselves: nothing left to do
+			r.Missing = true
+			r.Modified = false
+		}
+		r.Content = []byte(stripped)
+		r.Delete = strings.TrimSpace(stripped) == ""
+		removals = append(removals, r)
+	}
+	return removals, nil
+}
+
+// ApplyUninstall performs the planned removals, deletes the manifest and
+// prunes any directories left empty beneath root
+func ApplyUninstall(root string, removals []Removal) error {
+	dirs := map[string]bool{path.Dir(ManifestPath): true}
+	for _, r := range removals {
+		if r.Missing {
+			continue
+		}
+		if err := checkPath(r.Entry.Path); err != nil {
+			return err
+		}
+
+		target := filepath.Join(root, filepath.FromSlash(r.Entry.Path))
+		if r.Delete {
+			if err := os.Remove(target); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", r.Entry.Path, err)
+			}
+			dirs[path.Dir(r.Entry.Path)] = true
+			continue
+		}
+		if err := os.WriteFile(target, r.Content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", r.Entry.Path, err)
+		}
+	}
+
+	manifest := filepath.Join(root, filepath.FromSlash(ManifestPath))
+	if err := os.Remove(manifest); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove manifest: %w", err)
+	}
+
+	for dir := range dirs {
+		pruneEmptyDirs(root, dir)
+	}
+	return nil
+}
+
+// pruneEmptyDirs removes dir and then each of its parents, stopping at root or
+// at the first directory that is not empty
+func pruneEmptyDirs(root, dir string) {
+	for dir != "." && dir != "/" && dir != "" {
+		// os.Remove refuses to delete non-empty directories
+		if err := os.Remove(filepath.Join(root, filepath.FromSlash(dir))); err != nil {
+			return
+		}
+		dir = path.Dir(dir)
+	}
+}
@@ -0,0 +1,99 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanAndApplyUninstall(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "uninstall-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	write := func(path, content string) {
+		full := filepath.Join(tempDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(".github/kit/owned.md", "kit\n")
+	write(".github/kit/edited.md", "kit, edited\n")
+	write(".github/shared.md", "ours\n\n"+BlockBegin+"\nkit\n"+BlockEnd+"\n")
+	write(".github/block-only.md", BlockBegin+"\nkit\n"+BlockEnd+"\n")
+	write(".github/unhashed.md", "kit\n")
+
+	m := &Manifest{}
+	hash := HashContent([]byte("kit\n"))
+	m.Set(ManifestEntry{Path: ".github/kit/owned.md", Kind: KindFile, SHA256: hash})
+	m.Set(ManifestEntry{Path: ".github/kit/edited.md", Kind: KindFile, SHA256: hash})
+	m.Set(ManifestEntry{Path: ".github/kit/gone.md", Kind: KindFile, SHA256: hash})
+	m.Set(ManifestEntry{Path: ".github/shared.md", Kind: KindBlock, SHA256: hash})
+	m.Set(ManifestEntry{Path: ".github/block-only.md", Kind: KindBlock, SHA256: hash})
+	m.Set(ManifestEntry{Path: ".github/unhashed.md", Kind: KindFile})
+	if err := m.Save(tempDir); err != nil {
+		t.Fatal(err)
+	}
+
+	removals, err := PlanUninstall(tempDir, m)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := map[string]Removal{
+		".github/kit/owned.md":  {Delete: true},
+		".github/kit/edited.md": {Delete: true, Modified: true},
+		".github/kit/gone.md":   {Missing: true},
+		".github/shared.md":     {Content: []byte("ours\n")},
+		".github/block-only.md": {Delete: true},
+		".github/unhashed.md":   {Delete: true, Modified: true},
+	}
+	for _, r := range removals {
+		want := expected[r.Entry.Path]
+		if r.Delete != want.Delete || r.Modified != want.Modified || r.Missing != want.Missing {
+			t.Errorf("Unexpected removal for %s: %+v", r.Entry.Path, r)
+		}
+		if want.Content != nil && string(r.Content) != string(want.Content) {
+			t.Errorf("Expected %s to become %q, got %q", r.Entry.Path, want.Content, r.Content)
+		}
+	}
+
+	if err := ApplyUninstall(tempDir, removals); err != nil {
+		t.Fatalf("Unexpected error applying: %v", err)
+	}
+
+	for _, gone := range []string{".github/kit", ".github/block-only.md", ".github/unhashed.md", ManifestPath} {
+		if _, err := os.Stat(filepath.Join(tempDir, filepath.FromSlash(gone))); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", gone)
+		}
+	}
+	content, err := os.ReadFile(filepath.Join(tempDir, ".github", "shared.md"))
+	if err != nil || string(content) != "ours\n" {
+		t.Errorf("Expected shared.md to keep the user's content, got %q, %v", content, err)
+	}
+}
+
+func TestPlanUninstallRejectsOutsidePaths(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "uninstall-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	for _, p := range []string{"../precious.txt", ".github/../README.md", "/etc/passwd", "README.md"} {
+		m := &Manifest{}
+		m.Set(ManifestEntry{Path: p, Kind: KindFile})
+		if _, err := PlanUninstall(tempDir, m); err == nil {
+			t.Errorf("Expected %s to be refused", p)
+		}
+		if err := ApplyUninstall(tempDir, []Removal{{Entry: ManifestEntry{Path: p}, Delete: true}}); err == nil {
+			t.Errorf("Expected removing %s to be refused", p)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package installer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/johnayoung/go-agent-kit/internal/diff"
+)
+
+// PlanUpgrade works out how to bring each generated file beneath root up to
+// date while keeping local customizations. For files the user has edited,
+// the content recorded in the manifest at install time is the common
+// ancestor of a three-way merge between the user's file and the newly
+// generated content. Regions both changed are written with conflict markers
+// and flagged with Conflict. Files edited without a recorded ancestor cannot
+// be merged and are planned as ActionSkip.
+func PlanUpgrade(root string, files []File, m *Manifest, theirsLabel string) ([]Operation, error) {
+	ops := make([]Operation, 0, len(files))
+	for _, f := range files {
+		op := Operation{Path: f.Path, Desired: f.Content, Template: f.Template, Block: f.Block}
+
+		current, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(f.Path)))
+		if errors.Is(err, fs.ErrNotExist) {
+			op.Action = ActionCreate
+			ops = append(ops, op)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Path, err)
+		}
+		op.Current = current
+
+		entry, recorded := m.Entry(f.Path)
+		labels := diff.Labels{
+			Ours:   f.Path + " (your version)",
+			Base:   "installed " + entry.Version,
+			Theirs: theirsLabel,
+		}
+		switch {
+		case bytes.Equal(current, f.Content):
+			op.Action = ActionUnchanged
+		case m.Unmodified(f.Path, current):
+			op.Action = ActionUpdate
+		case f.Edited:
+			// Nothing recorded to merge the edits against
+			op.Action = ActionSkip
+		case f.Block:
+			if err := mergeBlock(&op, entry, labels); err != nil {
+				return nil, err
+			}
+		case !recorded || entry.Content == "":
+			op.Action = ActionSkip
+		default:
+			merged, conflict := diff.Merge3(entry.Content, string(current), string(f.Content), labels)
+			op.Generated = f.Content
+			op.Desired = []byte(merged)
+			op.Conflict = conflict
+			op.Action = ActionMerge
+			if bytes.Equal(op.Desired, current) {
+				op.Action = ActionUnchanged
+			}
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// mergeBlock three-way merges the managed block of a shared file whose block
+// was edited since install. Without a recorded ancestor, or when the user
+// removed the block, the newly generated block simply replaces it.
+func mergeBlock(op *Operation, entry ManifestEntry, labels diff.Labels) error {
+	op.Action = ActionUpdate
+
+	ours, found, err := ExtractBlock(string(op.Current))
+	if err != nil {
+		return fmt.Errorf("failed to read managed block in %s: %w", op.Path, err)
+	}
+	theirs, _, err := ExtractBlock(string(op.Desired))
+	if err != nil || !found || entry.Content == "" {
+		return err
+	}
+
+	merged, conflict := diff.Merge3(entry.Content, ours, theirs, labels)
+	content, err := UpsertBlock(string(op.Current), merged)
+	if err != nil {
+		return fmt.Errorf("failed to update managed block in %s: %w", op.Path, err)
+	}
+
+	op.Generated = []byte(theirs)
+	op.Desired = []byte(content)
+	op.Conflict = conflict
+	op.Action = ActionMerge
+	if bytes.Equal(op.Desired, op.Current) {
+		op.Action = ActionUnchanged
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package installer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanUpgrade(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "upgrade-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	write := func(path, content string) {
+		if err := os.WriteFile(filepath.Join(tempDir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldKit := "a\nb\nc\n"
+	newKit := "a\nb\nc\nd\n"
+	m := &Manifest{}
+	record := func(path, kind, content string) {
+		m.Set(ManifestEntry{Path: path, Kind: kind, Version: "0.1.0", Content: content, SHA256: HashContent([]byte(content))})
+	}
+
+	write("pristine.md", oldKit)
+	record("pristine.md", KindFile, oldKit)
+
+	write("custom.md", "A\nb\nc\n")
+	record("custom.md", KindFile, oldKit)
+
+	write("unrecorded.md", "mine\n")
+
+	write("shared.md", "ours\n\n"+BlockBegin+"\nA\nb\nc\n"+BlockEnd+"\n")
+	record("shared.md", KindBlock, oldKit)
+	write("legacy.md", "old kit\nteam notes\n")
+	legacyDesired, _ := UpsertBlock("", newKit)
+
+	sharedDesired, _ := UpsertBlock("ours\n\n"+BlockBegin+"\nA\nb\nc\n"+BlockEnd+"\n", newKit)
+
+	ops, err := PlanUpgrade(tempDir, []File{
+		{Path: "pristine.md", Content: []byte(newKit)},
+		{Path: "custom.md", Content: []byte(newKit)},
+		{Path: "unrecorded.md", Content: []byte(newKit)},
+		{Path: "shared.md", Content: []byte(sharedDesired), Block: true},
+		{Path: "missing.md", Content: []byte(newKit)},
+		{Path: "legacy.md", Content: []byte(legacyDesired), Block: true, Edited: true},
+	}, m, "new")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		action  Action
+		desired string
+	}{
+		{ActionUpdate, newKit},
+		{ActionMerge, "A\nb\nc\nd\n"},
+		{ActionSkip, newKit},
+		{ActionMerge, "ours\n\n" + BlockBegin + "\nA\nb\nc\nd\n" + BlockEnd + "\n"},
+		{ActionCreate, newKit},
+		{ActionSkip, legacyDesired},
+	}
+	for i, op := range ops {
+		if op.Action != expected[i].action {
+			t.Errorf("Expected %s to be planned as %s, got %s", op.Path, expected[i].action, op.Action)
+		}
+		if string(op.Desired) != expected[i].desired {
+			t.Errorf("Expected %s to become %q, got %q", op.Path, expected[i].desired, op.Desired)
+		}
+		if op.Conflict {
+			t.Errorf("Did not expect a conflict in %s", op.Path)
+		}
+	}
+
+	// Merged entries record the new generated content as the next ancestor
+	m.Record(ops, "0.2.0")
+	for _, path := range []string{"custom.md", "shared.md"} {
+		entry, _ := m.Entry(path)
+		if entry.Content != newKit || entry.Version != "0.2.0" {
+			t.Errorf("Expected %s to record the new kit content, got %+v", path, entry)
+		}
+	}
+	if entry, _ := m.Entry("unrecorded.md"); entry.Path != "" {
+		t.Error("Skipped files should not be recorded")
+	}
+}
+
+func TestPlanUpgradeConflict(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "upgrade-conflict-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := os.WriteFile(filepath.Join(tempDir, "f.md"), []byte("mine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := &Manifest{}
+	m.Set(ManifestEntry{Path: "f.md", Kind: KindFile, Version: "0.1.0", Content: "old\n", SHA256: HashContent([]byte("old\n"))})
+
+	ops, err := PlanUpgrade(tempDir, []File{{Path: "f.md", Content: []byte("new\n")}}, m, "go-agent-kit 0.2.0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !ops[0].Conflict {
+		t.Fatal("Expected a conflict")
+	}
+	for _, marker := range []string{"<<<<<<< f.md (your version)", "||||||| installed 0.1.0", "=======", ">>>>>>> go-agent-kit 0.2.0"} {
+		if !strings.Contains(string(ops[0].Desired), marker) {
+			t.Errorf("Expected conflict marker %q in:\n%s", marker, ops[0].Desired)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package pack
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/johnayoung/go-agent-kit/internal/templates"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigPath is the project configuration pinning template packs, relative to
+// the repository root
+const ConfigPath = ".go-agent-kit/config.yaml"
+
+// validCommit matches a full SHA-1 or SHA-256 git commit hash
+var validCommit = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// Config is the project configuration
+type Config struct {
+	Packs []Pin `yaml:"packs,omitempty"`
+
+	// Gates replace the gate commands of workflow stages, by workflow name
+	// and stage number, e.g. feat: {4: [make test-all]}
+	Gates map[string]map[int][]string `yaml:"gates,omitempty"`
+}
+
+// Pin records the exact commit of a pack a project uses
+type Pin struct {
+	Name    string `yaml:"name"`
+	URL     string `yaml:"url"`
+	Ref     string `yaml:"ref,omitempty"` // as requested, e.g. a tag
+	Commit  string `yaml:"commit"`        // what the ref resolved to
+	Version string `yaml:"version"`
+
+	// Checksum covers the content of the pack files at Commit
+	Checksum string `yaml:"checksum,omitempty"`
+	// PublicKey is the base64 ed25519 key the pack must be signed with
+	PublicKey string `yaml:"public_key,omitempty"`
+}
+
+// LoadConfig reads the project configuration beneath root. A missing file is
+// an empty configuration.
+func LoadConfig(root string) (*Config, error) {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(ConfigPath)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project config: %w", err)
+	}
+
+	var c Config
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ConfigPath, err)
+	}
+	for _, pin := range c.Packs {
+		// The commit names the pack's cache directory
+		if !validCommit.MatchString(pin.Commit) {
+			return nil, fmt.Errorf("invalid %s: pack %s is pinned to %q, not a full commit hash", ConfigPath, pin.Name, pin.Commit)
+		}
+	}
+	return &c, nil
+}
+
+// Save writes the configuration beneath root, packs sorted by name
+func (c *Config) Save(root string) error {
+	sort.Slice(c.Packs, func(i, j int) bool { return c.Packs[i].Name < c.Packs[j].Name })
+
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode project config: %w", err)
+	}
+
+	path := filepath.Join(root, filepath.FromSlash(ConfigPath))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write project config: %w", err)
+	}
+	return nil
+}
+
+// Pin returns the pin for the named pack
+func (c *Config) Pin(name string) (Pin, bool) {
+	for _, p := range c.Packs {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Pin{}, false
+}
+
+// Set adds or replaces the pin for a pack
+func (c *Config) Set(pin Pin) {
+	for i, p := range c.Packs {
+		if p.Name == pin.Name {
+			c.Packs[i] = pin
+			return
+		}
+	}
+	c.Packs = append(c.Packs, pin)
+}
+
+// Remove drops the pin for the named pack, reporting whether it existed
+func (c *Config) Remove(name string) bool {
+	for i, p := range c.Packs {
+		if p.Name == name {
+			c.Packs = append(c.Packs[:i], c.Packs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Layers returns a template layer for every pack pinned by the project at
+// root, in configuration order, fetching packs missing from the cache.
+// Unsigned packs are refused unless allowUnsigned is set.
+func Layers(root string, allowUnsigned bool) ([]templates.Layer, error) {
+	c, err := LoadConfig(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var layers []templates.Layer
+	for _, pin := range c.Packs {
+		p, err := Ensure(pin, allowUnsigned)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, p.Layer())
+	}
+	return layers, nil
+}
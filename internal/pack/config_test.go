@@ -0,0 +1,82 @@
+package pack
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var (
+	commit1 = strings.Repeat("1", 40)
+	commit2 = strings.Repeat("2", 40)
+	commit3 = strings.Repeat("3", 64)
+)
+
+func TestConfig(t *testing.T) {
+	root := t.TempDir()
+
+	config, err := LoadConfig(root)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if len(config.Packs) != 0 {
+		t.Fatalf("Expected an empty config, got %+v", config)
+	}
+
+	config.Set(Pin{Name: "zeta", URL: "z.git", Commit: commit1, Version: "1.0.0"})
+	config.Set(Pin{Name: "acme", URL: "a.git", Ref: "v1", Commit: commit2, Version: "1.0.0"})
+	config.Set(Pin{Name: "acme", URL: "a.git", Ref: "v2", Commit: commit3, Version: "2.0.0"})
+	if err := config.Save(root); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadConfig(root)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	expected := []Pin{
+		{Name: "acme", URL: "a.git", Ref: "v2", Commit: commit3, Version: "2.0.0"},
+		{Name: "zeta", URL: "z.git", Commit: commit1, Version: "1.0.0"},
+	}
+	if !reflect.DeepEqual(loaded.Packs, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, loaded.Packs)
+	}
+
+	if !loaded.Remove("zeta") || loaded.Remove("zeta") {
+		t.Error("Expected Remove to report whether the pack was pinned")
+	}
+	if _, ok := loaded.Pin("acme"); !ok {
+		t.Error("Expected acme to stay pinned")
+	}
+
+	loaded.Gates = map[string]map[int][]string{"feat": {4: {"make test-all"}}}
+	if err := loaded.Save(root); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	reloaded, err := LoadConfig(root)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if !reflect.DeepEqual(reloaded.Gates, loaded.Gates) {
+		t.Errorf("Expected gates %+v, got %+v", loaded.Gates, reloaded.Gates)
+	}
+}
+
+func TestLoadConfigRejectsInvalidCommits(t *testing.T) {
+	root := t.TempDir()
+
+	for _, commit := range []string{"../../../etc", "abc123", strings.Repeat("G", 40)} {
+		config := "packs:\n  - name: acme\n    url: a.git\n    commit: " + commit + "\n    version: 1.0.0\n"
+		if err := os.MkdirAll(filepath.Join(root, ".go-agent-kit"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(ConfigPath)), []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfig(root); err == nil {
+			t.Errorf("Expected commit %q to be rejected", commit)
+		}
+	}
+}
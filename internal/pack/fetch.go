@@ -0,0 +1,225 @@
+package pack
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Source is a pack location given as <git-url>@<ref>
+type Source struct {
+	URL string
+	Ref string // branch, tag or commit; empty for the default branch
+}
+
+// ParseSource splits "<git-url>@<ref>". The ref is optional and may contain
+// slashes; an "@" before the path of the URL, as in git@github.com:org/pack.git
+// or ssh://git@github.com/org/pack.git, belongs to the user name and is not
+// a ref.
+func ParseSource(s string) (Source, error) {
+	src := Source{URL: s}
+	if i := strings.LastIndex(s, "@"); i >= 0 && i >= pathStart(s) {
+		src.URL, src.Ref = s[:i], s[i+1:]
+		if src.Ref == "" {
+			return src, fmt.Errorf("invalid pack source %q: empty ref after @", s)
+		}
+	}
+	if src.URL == "" {
+		return src, fmt.Errorf("invalid pack source %q: missing git URL", s)
+	}
+	return src, nil
+}
+
+// pathStart returns the index where the path of a git URL begins: after the
+// host of scheme://[user@]host/path, after the colon of scp-style
+// [user@]host:path, and at the start of a local path
+func pathStart(s string) int {
+	if scheme, rest, ok := strings.Cut(s, "://"); ok {
+		if i := strings.Index(rest, "/"); i >= 0 {
+			return len(scheme) + len("://") + i
+		}
+		return len(s)
+	}
+	if i := strings.Index(s, ":"); i >= 0 && !strings.Contains(s[:i], "/") {
+		return i + 1
+	}
+	return 0
+}
+
+// String formats the source as <git-url>@<ref>
+func (s Source) String() string {
+	if s.Ref == "" {
+		return s.URL
+	}
+	return s.URL + "@" + s.Ref
+}
+
+// CacheDir returns the directory packs are cloned into,
+// $XDG_CACHE_HOME/go-agent-kit/packs (default ~/.cache/go-agent-kit/packs)
+func CacheDir() (string, error) {
+	cache := os.Getenv("XDG_CACHE_HOME")
+	if cache == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+		}
+		cache = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(cache, "go-agent-kit", "packs"), nil
+}
+
+// Fetch clones the pack at src into the cache, checks out its ref, verifies
+// it and authenticates it against trust. It returns the pack and the commit
+// the ref resolved to.
+func Fetch(src Source, trust Trust) (*Pack, string, error) {
+	ref := src.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	p, commit, err := checkout(src.URL, func(dir string) (string, error) {
+		return resolveRef(dir, ref)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if err := p.Authenticate(trust); err != nil {
+		return nil, "", err
+	}
+	return p, commit, nil
+}
+
+// Ensure returns the pinned pack, cloning it again if it is no longer in the
+// cache. Either way the checkout is verified, authenticated against the
+// pin's public key and compared with the pinned checksum.
+func Ensure(pin Pin, allowUnsigned bool) (*Pack, error) {
+	cache, err := CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(cache, pin.Commit)
+	if _, err := os.Stat(dir); err == nil {
+		return openPinned(dir, pin, allowUnsigned)
+	}
+
+	p, _, err := checkout(pin.URL, func(dir string) (string, error) {
+		return resolveRef(dir, pin.Commit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return openPinned(p.Dir, pin, allowUnsigned)
+}
+
+// openPinned opens a cached checkout and checks that it is the pinned pack
+func openPinned(dir string, pin Pin, allowUnsigned bool) (*Pack, error) {
+	p, err := Open(dir)
+	if err != nil {
+		return nil, fmt.Errorf("pack %s: %w", pin.Name, err)
+	}
+	if p.Manifest.Name != pin.Name {
+		return nil, fmt.Errorf("pack %s: commit %s contains pack %s", pin.Name, pin.Commit, p.Manifest.Name)
+	}
+	if err := p.Authenticate(Trust{PublicKey: pin.PublicKey, AllowUnsigned: allowUnsigned}); err != nil {
+		return nil, err
+	}
+
+	if pin.Checksum != "" {
+		checksum, err := p.Checksum()
+		if err != nil {
+			return nil, err
+		}
+		if checksum != pin.Checksum {
+			return nil, fmt.Errorf("pack %s has been tampered with: content does not match the checksum pinned in %s", pin.Name, ConfigPath)
+		}
+	}
+	return p, nil
+}
+
+// checkout clones url into a temporary directory in the cache, checks out
+// the commit chosen by resolve, verifies the pack and moves it to its final
+// location named after the commit
+func checkout(url string, resolve func(dir string) (string, error)) (*Pack, string, error) {
+	cache, err := CacheDir()
+	if err != nil {
+		return nil, "", err
+	}
+	if err := os.MkdirAll(cache, 0755); err != nil {
+		return nil, "", fmt.Errorf("failed to create pack cache: %w", err)
+	}
+
+	tmp, err := os.MkdirTemp(cache, ".clone-*")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create pack cache: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := git("", "clone", "--quiet", "--", url, tmp); err != nil {
+		return nil, "", fmt.Errorf("failed to clone %s: %w", url, err)
+	}
+	commit, err := resolve(tmp)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := git(tmp, "checkout", "--quiet", "--detach", commit); err != nil {
+		return nil, "", fmt.Errorf("failed to check out %s: %w", commit, err)
+	}
+	if _, err := Open(tmp); err != nil {
+		return nil, "", err
+	}
+
+	// An earlier fetch of the same commit may already be in the cache
+	dir := filepath.Join(cache, commit)
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		if err := os.Rename(tmp, dir); err != nil {
+			return nil, "", fmt.Errorf("failed to store pack in cache: %w", err)
+		}
+	}
+
+	p, err := Open(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	return p, commit, nil
+}
+
+// resolveRef returns the commit a branch, tag or commit refers to in the
+// clone at dir. Branches other than the default only exist as origin/<ref>.
+func resolveRef(dir, ref string) (string, error) {
+	for _, candidate := range []string{ref, "origin/" + ref} {
+		out, err := gitOutput(dir, "rev-parse", "--verify", "--quiet", candidate+"^{commit}")
+		if err == nil {
+			return strings.TrimSpace(out), nil
+		}
+	}
+	return "", fmt.Errorf("ref %q not found", ref)
+}
+
+// git runs a git command in dir
+func git(dir string, args ...string) error {
+	_, err := gitOutput(dir, args...)
+	return err
+}
+
+// gitOutput runs a git command in dir and returns its standard output
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	// Never stop to ask for credentials
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,205 @@
+package pack
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Integrity files at the root of a pack. SumsFile lists the SHA-256 of every
+// pack file in sha256sum format; SignatureFile holds a base64 ed25519
+// signature of SumsFile.
+const (
+	SumsFile      = "SHA256SUMS"
+	SignatureFile = "SHA256SUMS.sig"
+)
+
+// ErrUnsigned reports a pack without a valid signature from a trusted key
+var ErrUnsigned = errors.New("not signed by a trusted key")
+
+// Trust decides which packs are accepted
+type Trust struct {
+	// PublicKey is the base64 ed25519 key the pack must be signed with
+	PublicKey string
+	// AllowUnsigned accepts packs without a trusted signature. Packs whose
+	// checksums or signature do not match are refused regardless.
+	AllowUnsigned bool
+}
+
+// Files returns the pack files covered by its checksums: the manifest and
+// the template of every workflow, as slash paths relative to the pack root
+func (p *Pack) Files() []string {
+	files := []string{ManifestFile}
+	for _, name := range p.Manifest.Workflows {
+		files = append(files, p.templateFile(name))
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Sums computes the SumsFile content for the pack as it is on disk
+func (p *Pack) Sums() (string, error) {
+	var b strings.Builder
+	for _, file := range p.Files() {
+		sum, err := hashFile(p.Dir, file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s  %s\n", sum, file)
+	}
+	return b.String(), nil
+}
+
+// Checksum identifies the exact content of the pack files, for pinning
+func (p *Pack) Checksum() (string, error) {
+	sums, err := p.Sums()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(sums))
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// Authenticate checks the pack's checksums and signature against trust. A
+// pack whose files do not match its SumsFile, or whose signature does not
+// verify, is always refused; a pack that is merely unsigned is refused
+// (with ErrUnsigned) unless trust allows it.
+func (p *Pack) Authenticate(trust Trust) error {
+	name := p.Manifest.Name
+
+	sums, err := readPackFile(p.Dir, SumsFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p.unsigned(trust, "it has no "+SumsFile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", SumsFile, err)
+	}
+	if err := p.verifySums(sums); err != nil {
+		return fmt.Errorf("pack %s has been tampered with: %w", name, err)
+	}
+
+	if trust.PublicKey == "" {
+		return p.unsigned(trust, "no public key is configured for it")
+	}
+	key, err := ParsePublicKey(trust.PublicKey)
+	if err != nil {
+		return err
+	}
+	sig, err := readPackFile(p.Dir, SignatureFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p.unsigned(trust, "it has no "+SignatureFile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", SignatureFile, err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil || !ed25519.Verify(key, sums, signature) {
+		return fmt.Errorf("pack %s has been tampered with: %s does not verify with the trusted key", name, SignatureFile)
+	}
+	return nil
+}
+
+// unsigned refuses an unsigned pack unless trust allows it
+func (p *Pack) unsigned(trust Trust, reason string) error {
+	if trust.AllowUnsigned {
+		return nil
+	}
+	return fmt.Errorf("pack %s is %w: %s (use --allow-unsigned to accept it anyway)", p.Manifest.Name, ErrUnsigned, reason)
+}
+
+// verifySums checks every pack file against the SumsFile content
+func (p *Pack) verifySums(data []byte) error {
+	listed := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		sum, file, ok := strings.Cut(line, "  ")
+		if !ok {
+			return fmt.Errorf("malformed %s line %q", SumsFile, line)
+		}
+		listed[strings.TrimPrefix(file, "*")] = sum
+	}
+
+	for _, file := range p.Files() {
+		want, ok := listed[file]
+		if !ok {
+			return fmt.Errorf("%s is not listed in %s", file, SumsFile)
+		}
+		got, err := hashFile(p.Dir, file)
+		if err != nil {
+			return err
+		}
+		if got != want {
+			return fmt.Errorf("%s does not match its checksum in %s", file, SumsFile)
+		}
+	}
+	return nil
+}
+
+// Sign writes SumsFile and its SignatureFile for the pack in dir
+func Sign(dir string, key ed25519.PrivateKey) error {
+	p, err := Open(dir)
+	if err != nil {
+		return err
+	}
+	sums, err := p.Sums()
+	if err != nil {
+		return err
+	}
+
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(sums)))
+	if err := os.WriteFile(filepath.Join(dir, SumsFile), []byte(sums), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", SumsFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, SignatureFile), []byte(signature+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", SignatureFile, err)
+	}
+	return nil
+}
+
+// EncodeKey formats an ed25519 public or private key as base64
+func EncodeKey(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// ParsePublicKey decodes a base64 ed25519 public key
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key: expected %d base64-encoded ed25519 bytes", ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// ParsePrivateKey decodes a base64 ed25519 private key
+func ParsePrivateKey(s string) (ed25519.PrivateKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid private key: expected %d base64-encoded ed25519 bytes", ed25519.PrivateKeySize)
+	}
+	return ed25519.PrivateKey(key), nil
+}
+
+// hashFile returns the hex SHA-256 of file, a slash path inside the pack
+// checked out in dir
+func hashFile(dir, file string) (string, error) {
+	data, err := readPackFile(dir, file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
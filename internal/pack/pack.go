@@ -0,0 +1,199 @@
+// Package pack handles template packs: git repositories of workflow
+// templates published for many projects to install
+package pack
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/johnayoung/go-agent-kit/internal/templates"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the pack manifest at the root of a pack repository
+const ManifestFile = "pack.yaml"
+
+// DefaultTemplatesDir is where a pack keeps its templates unless its
+// manifest says otherwise
+const DefaultTemplatesDir = "templates"
+
+// validName matches pack and workflow names, which become file names
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// Manifest describes a template pack
+type Manifest struct {
+	Name        string   `yaml:"name"`
+	Version     string   `yaml:"version"`
+	Description string   `yaml:"description"`
+	Templates   string   `yaml:"templates,omitempty"` // template directory, default "templates"
+	Workflows   []string `yaml:"workflows"`
+}
+
+// Pack is a verified pack checked out on disk
+type Pack struct {
+	Manifest Manifest
+	Dir      string // root of the checkout
+}
+
+// templatesPath returns the template directory as a slash path relative to
+// the pack root
+func (p *Pack) templatesPath() string {
+	dir := p.Manifest.Templates
+	if dir == "" {
+		dir = DefaultTemplatesDir
+	}
+	return path.Clean(dir)
+}
+
+// templateFile returns the template of a workflow as a slash path relative
+// to the pack root
+func (p *Pack) templateFile(name string) string {
+	return path.Join(p.templatesPath(), name+".md")
+}
+
+// Layer returns the workflows listed in the manifest as a template layer
+func (p *Pack) Layer() templates.Layer {
+	listed := make(map[string]bool, len(p.Manifest.Workflows))
+	for _, name := range p.Manifest.Workflows {
+		listed[name+".md"] = true
+	}
+	return templates.Layer{
+		Name: "pack " + p.Manifest.Name,
+		FS:   listedFS{dir: p.Dir, templates: p.templatesPath(), listed: listed},
+	}
+}
+
+// listedFS exposes only the listed files of a template directory, so
+// templates a pack does not declare are never installed
+type listedFS struct {
+	dir       string // root of the pack checkout
+	templates string // template directory, relative to dir
+	listed    map[string]bool
+}
+
+func (l listedFS) Open(name string) (fs.File, error) {
+	if !l.listed[name] {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	file, err := regularFile(l.dir, path.Join(l.templates, name))
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return os.Open(file)
+}
+
+func (l listedFS) Glob(pattern string) ([]string, error) {
+	var matches []string
+	for name := range l.listed {
+		ok, err := path.Match(pattern, name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// Open reads and verifies the pack checked out in dir
+func Open(dir string) (*Pack, error) {
+	data, err := readPackFile(dir, ManifestFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("not a template pack: %s is missing", ManifestFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pack manifest: %w", err)
+	}
+
+	p := &Pack{Dir: dir}
+	if err := yaml.Unmarshal(data, &p.Manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse pack manifest: %w", err)
+	}
+	if err := p.Verify(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Verify checks that the manifest is complete and that every workflow it
+// lists is a valid template named as listed
+func (p *Pack) Verify() error {
+	m := p.Manifest
+	if !validName.MatchString(m.Name) {
+		return fmt.Errorf("invalid pack manifest: name %q must be lowercase letters, digits, '.', '_' or '-'", m.Name)
+	}
+	if m.Version == "" {
+		return fmt.Errorf("invalid pack manifest: %s has no version", m.Name)
+	}
+	if !filepath.IsLocal(filepath.FromSlash(m.Templates)) && m.Templates != "" {
+		return fmt.Errorf("invalid pack manifest: templates directory %q is outside the pack", m.Templates)
+	}
+	if len(m.Workflows) == 0 {
+		return fmt.Errorf("invalid pack manifest: %s lists no workflows", m.Name)
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range m.Workflows {
+		if !validName.MatchString(name) {
+			return fmt.Errorf("invalid pack manifest: workflow name %q", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("invalid pack manifest: workflow %s listed twice", name)
+		}
+		seen[name] = true
+
+		content, err := readPackFile(p.Dir, p.templateFile(name))
+		if err != nil {
+			return fmt.Errorf("pack %s: workflow %s has no template: %w", m.Name, name, err)
+		}
+		w, err := templates.ParseWorkflow(name, content)
+		if err != nil {
+			return fmt.Errorf("pack %s: %w", m.Name, err)
+		}
+		if _, err := w.Render(templates.Context{}); err != nil {
+			return fmt.Errorf("pack %s: %w", m.Name, err)
+		}
+	}
+	return nil
+}
+
+// readPackFile reads file, a slash path inside the pack checked out in dir
+func readPackFile(dir, file string) ([]byte, error) {
+	p, err := regularFile(dir, file)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(p)
+}
+
+// regularFile returns the path of file, a slash path inside the pack checked
+// out in dir, once it is known to be a regular file reached without
+// following symbolic links: a pack could otherwise have its templates read
+// from anywhere on the machine installing it
+func regularFile(dir, file string) (string, error) {
+	p := dir
+	var info fs.FileInfo
+	for _, elem := range strings.Split(path.Clean(file), "/") {
+		p = filepath.Join(p, elem)
+		var err error
+		if info, err = os.Lstat(p); err != nil {
+			return "", err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return "", fmt.Errorf("%s is a symbolic link", path.Join(path.Dir(file), elem))
+		}
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", file)
+	}
+	return p, nil
+}
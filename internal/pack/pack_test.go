@@ -0,0 +1,160 @@
+package pack
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johnayoung/go-agent-kit/internal/testutil"
+)
+
+// validPack is a minimal pack with one workflow
+var validPack = map[string]string{
+	"pack.yaml":           "name: acme\nversion: 1.0.0\nworkflows: [review]\n",
+	"templates/review.md": "---\ntitle: Review Workflow\n---\n# Review {{.Description}}\n",
+}
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		symlinks      map[string]string // link to target, relative to the pack
+		expectedError string
+	}{
+		{name: "valid pack", files: validPack},
+		{
+			name:          "missing manifest",
+			files:         map[string]string{"templates/review.md": "# Review\n"},
+			expectedError: "pack.yaml is missing",
+		},
+		{
+			name:          "invalid name",
+			files:         map[string]string{"pack.yaml": "name: Acme Pack\nversion: 1.0.0\nworkflows: [review]\n"},
+			expectedError: "name \"Acme Pack\"",
+		},
+		{
+			name:          "missing version",
+			files:         map[string]string{"pack.yaml": "name: acme\nworkflows: [review]\n"},
+			expectedError: "no version",
+		},
+		{
+			name:          "missing template",
+			files:         map[string]string{"pack.yaml": "name: acme\nversion: 1.0.0\nworkflows: [review]\n"},
+			expectedError: "workflow review has no template",
+		},
+		{
+			name: "broken template",
+			files: map[string]string{
+				"pack.yaml":           "name: acme\nversion: 1.0.0\nworkflows: [review]\n",
+				"templates/review.md": "# Review {{.Description\n",
+			},
+			expectedError: "failed to parse template review",
+		},
+		{
+			name: "template renaming its workflow",
+			files: map[string]string{
+				"pack.yaml":           "name: acme\nversion: 1.0.0\nworkflows: [review]\n",
+				"templates/review.md": "---\nname: fix\n---\n# Review\n",
+			},
+			expectedError: "must match the file name",
+		},
+		{
+			name: "templates outside the pack",
+			files: map[string]string{
+				"pack.yaml": "name: acme\nversion: 1.0.0\ntemplates: ../elsewhere\nworkflows: [review]\n",
+			},
+			expectedError: "outside the pack",
+		},
+		{
+			name: "template linking outside the pack",
+			files: map[string]string{
+				"pack.yaml":        "name: acme\nversion: 1.0.0\nworkflows: [review]\n",
+				"../secret/key.md": "# Secret\n",
+			},
+			symlinks:      map[string]string{"templates/review.md": "../../secret/key.md"},
+			expectedError: "templates/review.md is a symbolic link",
+		},
+		{
+			name: "template directory linking elsewhere",
+			files: map[string]string{
+				"pack.yaml":           "name: acme\nversion: 1.0.0\nworkflows: [review]\n",
+				"elsewhere/review.md": "# Review {{.Description}}\n",
+			},
+			symlinks:      map[string]string{"templates": "elsewhere"},
+			expectedError: "templates is a symbolic link",
+		},
+		{
+			name: "template that is a directory",
+			files: map[string]string{
+				"pack.yaml":                "name: acme\nversion: 1.0.0\nworkflows: [review]\n",
+				"templates/review.md/x.md": "",
+			},
+			expectedError: "templates/review.md is not a regular file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "pack")
+			testutil.WriteFiles(t, dir, tt.files)
+			for link, target := range tt.symlinks {
+				if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, link)), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Symlink(target, filepath.Join(dir, link)); err != nil {
+					t.Skipf("symlinks are not supported: %v", err)
+				}
+			}
+
+			p, err := Open(dir)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("Expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if p.Manifest.Name != "acme" || p.Manifest.Version != "1.0.0" {
+				t.Errorf("Unexpected manifest: %+v", p.Manifest)
+			}
+		})
+	}
+}
+
+func TestLayerOnlyListsDeclaredWorkflows(t *testing.T) {
+	dir := t.TempDir()
+
+	testutil.WriteFiles(t, dir, validPack)
+	testutil.WriteFiles(t, dir, map[string]string{"templates/draft.md": "# Not declared\n"})
+
+	p, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	layer := p.Layer()
+	if layer.Name != "pack acme" {
+		t.Errorf("Unexpected layer name %q", layer.Name)
+	}
+	if _, err := layer.FS.Open("draft.md"); err == nil {
+		t.Error("Expected undeclared template to be hidden")
+	}
+	if _, err := layer.FS.Open("review.md"); err != nil {
+		t.Errorf("Expected declared template to be readable: %v", err)
+	}
+
+	// A template swapped for a link after the pack was opened
+	review := filepath.Join(dir, "templates", "review.md")
+	if err := os.Remove(review); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "templates", "draft.md"), review); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+	if _, err := layer.FS.Open("review.md"); err == nil || !strings.Contains(err.Error(), "symbolic link") {
+		t.Errorf("Expected a linked template to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,269 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Command kinds, in the order commands are listed
+const (
+	KindBuild  = "build"
+	KindTest   = "test"
+	KindLint   = "lint"
+	KindFormat = "format"
+	KindCheck  = "check" // runs several verifications at once
+)
+
+var kindOrder = map[string]int{KindBuild: 0, KindTest: 1, KindLint: 2, KindFormat: 3, KindCheck: 4}
+
+// Command is a verification command the project defines for itself
+type Command struct {
+	Kind   string
+	Run    string // what to type, e.g. "make test"
+	Source string // the file defining it, e.g. "Makefile"
+}
+
+// commandSource discovers commands from one kind of task runner file
+type commandSource struct {
+	files []string
+	// languages the runner belongs to; its commands only replace the
+	// language defaults for these. Empty for general-purpose runners.
+	languages []string
+	discover  func(root, name, content string) ([]Command, error)
+}
+
+// commandSources in order of precedence: when several define a command of
+// the same kind, the first one becomes the Facts command of that kind
+var commandSources = []commandSource{
+	{files: []string{"Makefile", "makefile", "GNUmakefile"}, discover: discoverMake},
+	{files: []string{"Taskfile.yml", "Taskfile.yaml"}, discover: discoverTaskfile},
+	{files: []string{"justfile", "Justfile", ".justfile"}, discover: discoverJust},
+	{files: []string{"package.json"}, languages: []string{"JavaScript", "TypeScript"}, discover: discoverScripts},
+	{files: []string{"tox.ini"}, languages: []string{"Python"}, discover: discoverTox},
+	{files: []string{"pyproject.toml"}, languages: []string{"Python"}, discover: discoverPyproject},
+}
+
+// discoverCommands finds the verification commands defined beneath root and
+// lets them replace the language defaults in facts. Task runner files are
+// optional, so one that cannot be read or parsed defines no commands rather
+// than failing the scan.
+func discoverCommands(root string, f *Facts) {
+	chosen := map[string]bool{}
+	for _, src := range commandSources {
+		for _, name := range src.files {
+			content, err := readFile(root, name)
+			if err != nil {
+				break
+			}
+			if content == "" {
+				continue
+			}
+			commands, err := src.discover(root, name, content)
+			if err != nil {
+				break
+			}
+			sort.SliceStable(commands, func(i, j int) bool { return kindOrder[commands[i].Kind] < kindOrder[commands[j].Kind] })
+			f.Commands = append(f.Commands, commands...)
+
+			// The project's own entry points win over the language defaults,
+			// but a frontend's package.json does not speak for a Go service
+			if len(src.languages) == 0 || contains(src.languages, f.Language) {
+				f.choose(commands, chosen)
+			}
+			break
+		}
+	}
+}
+
+// choose sets the build, test and lint commands from the first command of
+// each kind not chosen yet
+func (f *Facts) choose(commands []Command, chosen map[string]bool) {
+	for _, c := range commands {
+		if chosen[c.Kind] {
+			continue
+		}
+		chosen[c.Kind] = true
+		switch c.Kind {
+		case KindBuild:
+			f.BuildCommand = c.Run
+		case KindTest:
+			f.TestCommand = c.Run
+		case KindLint:
+			f.LintCommand = c.Run
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// classify returns the kind of a task from its name, or "" for tasks that
+// are not verification commands (run, deploy, clean, ...)
+func classify(task string) string {
+	name := strings.ToLower(task)
+	word := strings.FieldsFunc(name, func(r rune) bool { return r == ':' || r == '-' || r == '_' || r == '.' || r == '/' })
+	if len(word) == 0 {
+		return ""
+	}
+	switch word[0] {
+	case "build", "compile":
+		return KindBuild
+	case "test", "tests", "unittest", "pytest", "spec":
+		return KindTest
+	case "lint", "vet", "golangci", "clippy", "ruff", "flake8", "eslint", "typecheck", "mypy":
+		return KindLint
+	case "fmt", "format", "prettier", "black":
+		return KindFormat
+	case "check", "verify", "ci":
+		return KindCheck
+	}
+	return ""
+}
+
+// makeTarget matches a rule line "target: prerequisites", but not a ":="
+// assignment
+var makeTarget = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9_./-]*)\s*:([^=]|$)`)
+
+func discoverMake(_, name, content string) ([]Command, error) {
+	var commands []Command
+	seen := map[string]bool{}
+	for _, line := range strings.Split(content, "\n") {
+		m := makeTarget.FindStringSubmatch(line)
+		if m == nil || seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		if kind := classify(m[1]); kind != "" {
+			commands = append(commands, Command{Kind: kind, Run: "make " + m[1], Source: name})
+		}
+	}
+	return commands, nil
+}
+
+func discoverTaskfile(_, name, content string) ([]Command, error) {
+	var taskfile struct {
+		Tasks yaml.Node `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &taskfile); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	var commands []Command
+	// Mapping node content alternates keys and values, in file order
+	for i := 0; i+1 < len(taskfile.Tasks.Content); i += 2 {
+		task := taskfile.Tasks.Content[i].Value
+		if kind := classify(task); kind != "" {
+			commands = append(commands, Command{Kind: kind, Run: "task " + task, Source: name})
+		}
+	}
+	return commands, nil
+}
+
+// justRecipe matches a recipe header such as "test:", "@lint *args:" or
+// "build target='all': deps", but not an assignment "x := y"
+var justRecipe = regexp.MustCompile(`^@?([A-Za-z0-9_-]+)(\s+[^:=]*)?:([^=]|$)`)
+
+func discoverJust(_, name, content string) ([]Command, error) {
+	var commands []Command
+	for _, line := range strings.Split(content, "\n") {
+		m := justRecipe.FindStringSubmatch(line)
+		if m == nil || m[1] == "set" || m[1] == "alias" || m[1] == "export" {
+			continue
+		}
+		if kind := classify(m[1]); kind != "" {
+			commands = append(commands, Command{Kind: kind, Run: "just " + m[1], Source: name})
+		}
+	}
+	return commands, nil
+}
+
+func discoverScripts(root, name, content string) ([]Command, error) {
+	var pkg packageJSON
+	if err := json.Unmarshal([]byte(content), &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	pm := nodePackageManager(root, pkg)
+
+	scripts := make([]string, 0, len(pkg.Scripts))
+	for script := range pkg.Scripts {
+		scripts = append(scripts, script)
+	}
+	sort.Strings(scripts)
+
+	var commands []Command
+	for _, script := range scripts {
+		kind := classify(script)
+		if kind == "" {
+			continue
+		}
+		run := pm + " run " + script
+		if script == "test" {
+			run = pm + " test"
+		}
+		commands = append(commands, Command{Kind: kind, Run: run, Source: name})
+	}
+	return commands, nil
+}
+
+func discoverTox(_, name, content string) ([]Command, error) {
+	commands := []Command{{Kind: KindTest, Run: "tox", Source: name}}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		env, ok := strings.CutPrefix(line, "[testenv:")
+		if !ok {
+			continue
+		}
+		env = strings.TrimSuffix(env, "]")
+		if kind := classify(env); kind != "" && kind != KindTest {
+			commands = append(commands, Command{Kind: kind, Run: "tox -e " + env, Source: name})
+		}
+	}
+	return commands, nil
+}
+
+// pyprojectTools maps pyproject.toml tool sections to the command running
+// the tool
+var pyprojectTools = []struct {
+	section string
+	command Command
+}{
+	{"tool.pytest", Command{Kind: KindTest, Run: "pytest"}},
+	{"tool.ruff", Command{Kind: KindLint, Run: "ruff check ."}},
+	{"tool.mypy", Command{Kind: KindLint, Run: "mypy ."}},
+	{"tool.black", Command{Kind: KindFormat, Run: "black --check ."}},
+}
+
+func discoverPyproject(_, name, content string) ([]Command, error) {
+	pyproject := parseTOML(content)
+	var commands []Command
+	for _, tool := range pyprojectTools {
+		if pyproject.hasTable(tool.section) {
+			c := tool.command
+			c.Source = name
+			commands = append(commands, c)
+		}
+	}
+
+	// Poe the Poet tasks: [tool.poe.tasks] with "task = ..." entries
+	for _, e := range pyproject.Entries {
+		if e.Section != "tool.poe.tasks" {
+			continue
+		}
+		task, _, _ := strings.Cut(e.Key, ".")
+		if kind := classify(task); kind != "" {
+			commands = append(commands, Command{Kind: kind, Run: "poe " + task, Source: name})
+		}
+	}
+	return commands, nil
+}
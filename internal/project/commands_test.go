@@ -0,0 +1,156 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiscoverCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		commands []Command
+		test     string
+		build    string
+		lint     string
+	}{
+		{
+			name: "makefile targets replace go defaults",
+			files: map[string]string{
+				"go.mod":   "module example.com/svc\n",
+				"Makefile": ".PHONY: build test lint run\nVERSION := 1.0\n\nbuild: deps\n\tgo build -o bin/svc ./cmd/svc\n\ntest:\n\tgo test -race ./...\n\nlint:\n\tgolangci-lint run\n\nrun: build\n\t./bin/svc\n\ntest: integration\n",
+			},
+			commands: []Command{
+				{Kind: KindBuild, Run: "make build", Source: "Makefile"},
+				{Kind: KindTest, Run: "make test", Source: "Makefile"},
+				{Kind: KindLint, Run: "make lint", Source: "Makefile"},
+			},
+			build: "make build",
+			test:  "make test",
+			lint:  "make lint",
+		},
+		{
+			name: "taskfile in file order",
+			files: map[string]string{
+				"go.mod":       "module example.com/svc\n",
+				"Taskfile.yml": "version: '3'\ntasks:\n  lint:\n    cmds: [golangci-lint run]\n  test:unit:\n    cmds: [go test ./...]\n  deploy:\n    cmds: [./deploy.sh]\n",
+			},
+			commands: []Command{
+				{Kind: KindTest, Run: "task test:unit", Source: "Taskfile.yml"},
+				{Kind: KindLint, Run: "task lint", Source: "Taskfile.yml"},
+			},
+			build: "go build ./...",
+			test:  "task test:unit",
+			lint:  "task lint",
+		},
+		{
+			name: "justfile recipes",
+			files: map[string]string{
+				"Cargo.toml": "[package]\nname = \"tool\"\n",
+				"justfile":   "set shell := [\"bash\", \"-c\"]\nversion := \"1\"\n\n@test *args:\n    cargo test {{args}}\n\nfmt:\n    cargo fmt\n\nrelease target='x86': build\n    echo\n",
+			},
+			commands: []Command{
+				{Kind: KindTest, Run: "just test", Source: "justfile"},
+				{Kind: KindFormat, Run: "just fmt", Source: "justfile"},
+			},
+			build: "cargo build",
+			test:  "just test",
+			lint:  "cargo clippy",
+		},
+		{
+			name: "tox environments and poe tasks",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"svc\"\n\n[tool.pytest.ini_options]\naddopts = \"-q\"\n\n[tool.poe.tasks]\ntypecheck = \"mypy src\"\nserve = \"uvicorn app:app\"\n",
+				"tox.ini":        "[tox]\nenvlist = py311, lint\n\n[testenv]\ncommands = pytest\n\n[testenv:lint]\ncommands = ruff check .\n",
+			},
+			commands: []Command{
+				{Kind: KindTest, Run: "tox", Source: "tox.ini"},
+				{Kind: KindLint, Run: "tox -e lint", Source: "tox.ini"},
+				{Kind: KindTest, Run: "pytest", Source: "pyproject.toml"},
+				{Kind: KindLint, Run: "poe typecheck", Source: "pyproject.toml"},
+			},
+			build: "python -m build",
+			test:  "tox",
+			lint:  "tox -e lint",
+		},
+		{
+			name: "malformed taskfile is skipped",
+			files: map[string]string{
+				"go.mod":       "module example.com/svc\n",
+				"Makefile":     "test:\n\tgo test ./...\n",
+				"Taskfile.yml": "tasks: [lint\n",
+			},
+			commands: []Command{
+				{Kind: KindTest, Run: "make test", Source: "Makefile"},
+			},
+			build: "go build ./...",
+			test:  "make test",
+			lint:  "go vet ./...",
+		},
+		{
+			name: "frontend scripts do not replace go commands",
+			files: map[string]string{
+				"go.mod":       "module example.com/svc\n",
+				"package.json": `{"scripts": {"test": "jest", "lint": "eslint .", "start": "vite"}}`,
+			},
+			commands: []Command{
+				{Kind: KindTest, Run: "npm test", Source: "package.json"},
+				{Kind: KindLint, Run: "npm run lint", Source: "package.json"},
+			},
+			build: "go build ./...",
+			test:  "go test ./...",
+			lint:  "go vet ./...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := os.MkdirTemp("", "project-commands-test-*")
+			if err != nil {
+				t.Fatalf("Failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(root)
+
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			facts, err := Scan(root)
+			if err != nil {
+				t.Fatalf("Scan failed: %v", err)
+			}
+			if !reflect.DeepEqual(facts.Commands, tt.commands) {
+				t.Errorf("Expected commands %+v\ngot %+v", tt.commands, facts.Commands)
+			}
+			if facts.BuildCommand != tt.build || facts.TestCommand != tt.test || facts.LintCommand != tt.lint {
+				t.Errorf("Expected build %q, test %q, lint %q; got %q, %q, %q",
+					tt.build, tt.test, tt.lint, facts.BuildCommand, facts.TestCommand, facts.LintCommand)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := map[string]string{
+		"test":         KindTest,
+		"test:unit":    KindTest,
+		"test-e2e":     KindTest,
+		"Build":        KindBuild,
+		"lint:fix":     KindLint,
+		"vet":          KindLint,
+		"fmt":          KindFormat,
+		"check":        KindCheck,
+		"run":          "",
+		"deploy":       "",
+		"latest-build": "",
+	}
+	for name, expected := range tests {
+		if got := classify(name); got != expected {
+			t.Errorf("classify(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
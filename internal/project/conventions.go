@@ -0,0 +1,315 @@
+package project
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Convention summarizes the rules one linter or formatter configuration
+// enforces
+type Convention struct {
+	Tool   string // e.g. "golangci-lint"
+	Source string // the configuration file
+	Rules  []string
+}
+
+// conventionSource reads one kind of configuration file
+type conventionSource struct {
+	tool    string
+	files   []string
+	extract func(name, content string) ([]string, error)
+}
+
+var conventionSources = []conventionSource{
+	{tool: "EditorConfig", files: []string{".editorconfig"}, extract: extractEditorConfig},
+	{tool: "golangci-lint", files: []string{".golangci.yml", ".golangci.yaml", ".golangci.json"}, extract: extractGolangci},
+	{tool: "ESLint", files: []string{".eslintrc.json", ".eslintrc", ".eslintrc.yml", ".eslintrc.yaml", ".eslintrc.js", ".eslintrc.cjs", "eslint.config.js", "eslint.config.mjs"}, extract: extractESLint},
+	{tool: "Prettier", files: []string{".prettierrc", ".prettierrc.json", ".prettierrc.yml", ".prettierrc.yaml"}, extract: extractPrettier},
+	{tool: "Ruff", files: []string{"ruff.toml", ".ruff.toml"}, extract: extractRuff},
+	{tool: "Ruff", files: []string{"pyproject.toml"}, extract: extractPyprojectRuff},
+	{tool: "rustfmt", files: []string{"rustfmt.toml", ".rustfmt.toml"}, extract: extractRustfmt},
+}
+
+// discoverConventions summarizes the linter and formatter configurations
+// beneath root. A configuration that cannot be read or parsed is still
+// reported, so the agent knows to consult it, but never fails the scan.
+func discoverConventions(root string, f *Facts) {
+	for _, src := range conventionSources {
+		if f.hasConvention(src.tool) {
+			// e.g. ruff.toml takes precedence over pyproject.toml
+			continue
+		}
+		for _, name := range src.files {
+			content, err := readFile(root, name)
+			if err == nil && content == "" {
+				continue
+			}
+			var rules []string
+			if err == nil {
+				rules, err = src.extract(name, content)
+			}
+			if err != nil {
+				rules = []string{"Could not be read; check " + name + " directly"}
+			}
+			if len(rules) == 0 {
+				// e.g. a pyproject.toml without Ruff settings
+				continue
+			}
+			f.Conventions = append(f.Conventions, Convention{Tool: src.tool, Source: name, Rules: rules})
+			break
+		}
+	}
+}
+
+// hasConvention reports whether a configuration of tool was already found
+func (f *Facts) hasConvention(tool string) bool {
+	for _, c := range f.Conventions {
+		if c.Tool == tool {
+			return true
+		}
+	}
+	return false
+}
+
+// editorConfigKeys are the EditorConfig properties reported, in order
+var editorConfigKeys = []string{"indent_style", "indent_size", "tab_width", "max_line_length", "end_of_line", "charset", "trim_trailing_whitespace", "insert_final_newline"}
+
+func extractEditorConfig(name, content string) ([]string, error) {
+	var rules []string
+	section := ""
+	props := map[string]string{}
+
+	flush := func() {
+		var parts []string
+		for _, key := range editorConfigKeys {
+			if value, ok := props[key]; ok {
+				parts = append(parts, key+" = "+value)
+			}
+		}
+		if section != "" && len(parts) > 0 {
+			rules = append(rules, fmt.Sprintf("`%s`: %s", section, strings.Join(parts, ", ")))
+		}
+		props = map[string]string{}
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			flush()
+			section = strings.Trim(line, "[]")
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if ok {
+			props[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+	}
+	flush()
+	return rules, nil
+}
+
+// golangciSettings are linter settings worth stating, as linter, setting and
+// the sentence describing it
+var golangciSettings = []struct {
+	linter, setting, format string
+}{
+	{"lll", "line-length", "Maximum line length: %v"},
+	{"gocyclo", "min-complexity", "Maximum cyclomatic complexity: %v"},
+	{"cyclop", "max-complexity", "Maximum cyclomatic complexity: %v"},
+	{"gocognit", "min-complexity", "Maximum cognitive complexity: %v"},
+	{"funlen", "lines", "Maximum function length: %v lines"},
+	{"funlen", "statements", "Maximum function length: %v statements"},
+	{"nestif", "min-complexity", "Maximum nested if complexity: %v"},
+	{"goimports", "local-prefixes", "Group imports with local prefix %v last"},
+	{"misspell", "locale", "Spelling locale: %v"},
+	{"dupl", "threshold", "Duplicate code threshold: %v tokens"},
+}
+
+func extractGolangci(name, content string) ([]string, error) {
+	var config struct {
+		Linters struct {
+			Default    string                    `yaml:"default"`
+			EnableAll  bool                      `yaml:"enable-all"`
+			DisableAll bool                      `yaml:"disable-all"`
+			Enable     []string                  `yaml:"enable"`
+			Disable    []string                  `yaml:"disable"`
+			Settings   map[string]map[string]any `yaml:"settings"`
+		} `yaml:"linters"`
+		LintersSettings map[string]map[string]any `yaml:"linters-settings"`
+		Formatters      struct {
+			Enable   []string                  `yaml:"enable"`
+			Settings map[string]map[string]any `yaml:"settings"`
+		} `yaml:"formatters"`
+	}
+	// JSON is valid YAML
+	if err := yaml.Unmarshal([]byte(content), &config); err != nil {
+		return nil, err
+	}
+
+	var rules []string
+	l := config.Linters
+	switch {
+	case l.EnableAll || l.Default == "all":
+		rules = append(rules, "All linters are enabled by default")
+	case l.DisableAll || l.Default == "none":
+		rules = append(rules, "Only the listed linters are enabled")
+	}
+	if len(l.Enable) > 0 {
+		rules = append(rules, "Enabled linters: "+strings.Join(l.Enable, ", "))
+	}
+	if len(l.Disable) > 0 {
+		rules = append(rules, "Disabled linters: "+strings.Join(l.Disable, ", "))
+	}
+	if len(config.Formatters.Enable) > 0 {
+		rules = append(rules, "Formatters: "+strings.Join(config.Formatters.Enable, ", "))
+	}
+
+	// v1 keeps settings in linters-settings, v2 in linters.settings and
+	// formatters.settings
+	settings := map[string]map[string]any{}
+	for _, m := range []map[string]map[string]any{config.LintersSettings, l.Settings, config.Formatters.Settings} {
+		for linter, values := range m {
+			settings[linter] = values
+		}
+	}
+	for _, s := range golangciSettings {
+		if value, ok := settings[s.linter][s.setting]; ok {
+			rules = append(rules, fmt.Sprintf(s.format, value)+" ("+s.linter+")")
+		}
+	}
+	return rules, nil
+}
+
+func extractESLint(name, content string) ([]string, error) {
+	var config struct {
+		Extends any            `yaml:"extends"`
+		Plugins []string       `yaml:"plugins"`
+		Rules   map[string]any `yaml:"rules"`
+	}
+	if strings.HasSuffix(name, "js") {
+		// JavaScript configuration cannot be read without running it
+		return []string{"Configured in JavaScript; run the linter to see the rules"}, nil
+	}
+	if err := yaml.Unmarshal([]byte(content), &config); err != nil {
+		return nil, err
+	}
+
+	var rules []string
+	var extends []string
+	switch e := config.Extends.(type) {
+	case string:
+		extends = []string{e}
+	case []any:
+		for _, item := range e {
+			extends = append(extends, fmt.Sprint(item))
+		}
+	}
+	if len(extends) > 0 {
+		rules = append(rules, "Extends: "+strings.Join(extends, ", "))
+	}
+	if len(config.Plugins) > 0 {
+		rules = append(rules, "Plugins: "+strings.Join(config.Plugins, ", "))
+	}
+
+	var enforced []string
+	for rule, setting := range config.Rules {
+		severity := setting
+		if list, ok := setting.([]any); ok && len(list) > 0 {
+			severity = list[0]
+		}
+		switch fmt.Sprint(severity) {
+		case "off", "0":
+			continue
+		case "warn", "1":
+			enforced = append(enforced, rule+" (warn)")
+		default:
+			enforced = append(enforced, rule+" (error)")
+		}
+	}
+	sort.Strings(enforced)
+	if len(enforced) > 0 {
+		rules = append(rules, "Rules: "+strings.Join(enforced, ", "))
+	}
+	return rules, nil
+}
+
+func extractPrettier(name, content string) ([]string, error) {
+	var options map[string]any
+	if err := yaml.Unmarshal([]byte(content), &options); err != nil {
+		return nil, err
+	}
+	return sortedSettings(options), nil
+}
+
+func extractRuff(name, content string) ([]string, error) {
+	return ruffRules(parseTOML(content).Entries), nil
+}
+
+// extractPyprojectRuff reads the [tool.ruff] tables of a pyproject.toml
+func extractPyprojectRuff(name, content string) ([]string, error) {
+	var entries []tomlEntry
+	for _, e := range parseTOML(content).Entries {
+		if e.Section != "tool.ruff" && !strings.HasPrefix(e.Section, "tool.ruff.") {
+			continue
+		}
+		e.Section = strings.TrimPrefix(strings.TrimPrefix(e.Section, "tool.ruff"), ".")
+		entries = append(entries, e)
+	}
+	return ruffRules(entries), nil
+}
+
+// ruffRules summarizes Ruff settings, with sections relative to the Ruff
+// configuration (e.g. "lint" or "format")
+func ruffRules(entries []tomlEntry) []string {
+	var rules []string
+	for _, e := range entries {
+		switch e.Key {
+		case "select", "extend-select":
+			rules = append(rules, "Selected rules: "+strings.Join(tomlList(e.Value), ", "))
+		case "ignore", "extend-ignore":
+			rules = append(rules, "Ignored rules: "+strings.Join(tomlList(e.Value), ", "))
+		case "line-length":
+			rules = append(rules, "Maximum line length: "+tomlScalar(e.Value))
+		default:
+			if strings.HasPrefix(e.Value, "[") || strings.HasPrefix(e.Value, "{") {
+				// per-file ignores and similar are too detailed to summarize
+				continue
+			}
+			name := e.Key
+			if e.Section != "" {
+				name = e.Section + "." + e.Key
+			}
+			rules = append(rules, name+": "+tomlScalar(e.Value))
+		}
+	}
+	return rules
+}
+
+func extractRustfmt(name, content string) ([]string, error) {
+	var rules []string
+	for _, e := range parseTOML(content).Entries {
+		rules = append(rules, e.Key+": "+tomlScalar(e.Value))
+	}
+	return rules, nil
+}
+
+// sortedSettings formats top-level settings as "key: value", sorted by key
+func sortedSettings(settings map[string]any) []string {
+	var rules []string
+	for key, value := range settings {
+		switch value.(type) {
+		case map[string]any, []any:
+			// overrides and plugin lists are too detailed to summarize
+			continue
+		}
+		rules = append(rules, fmt.Sprintf("%s: %v", key, value))
+	}
+	sort.Strings(rules)
+	return rules
+}
@@ -0,0 +1,170 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiscoverConventions(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		conventions []Convention
+	}{
+		{
+			name: "golangci v1 and editorconfig",
+			files: map[string]string{
+				"go.mod":        "module example.com/svc\n",
+				".editorconfig": "root = true\n\n[*]\nend_of_line = lf\ninsert_final_newline = true\n\n# Go uses tabs\n[*.go]\nindent_style = tab\n\n[Makefile]\n",
+				".golangci.yml": "linters:\n  disable-all: true\n  enable:\n    - errcheck\n    - lll\n    - gocyclo\nlinters-settings:\n  lll:\n    line-length: 120\n  gocyclo:\n    min-complexity: 15\n  unparam:\n    check-exported: true\n",
+			},
+			conventions: []Convention{
+				{Tool: "EditorConfig", Source: ".editorconfig", Rules: []string{
+					"`*`: end_of_line = lf, insert_final_newline = true",
+					"`*.go`: indent_style = tab",
+				}},
+				{Tool: "golangci-lint", Source: ".golangci.yml", Rules: []string{
+					"Only the listed linters are enabled",
+					"Enabled linters: errcheck, lll, gocyclo",
+					"Maximum line length: 120 (lll)",
+					"Maximum cyclomatic complexity: 15 (gocyclo)",
+				}},
+			},
+		},
+		{
+			name: "golangci v2",
+			files: map[string]string{
+				".golangci.yaml": "version: \"2\"\nlinters:\n  default: standard\n  enable: [revive]\n  disable: [unused]\n  settings:\n    funlen:\n      lines: 60\nformatters:\n  enable: [gofumpt, goimports]\n",
+			},
+			conventions: []Convention{
+				{Tool: "golangci-lint", Source: ".golangci.yaml", Rules: []string{
+					"Enabled linters: revive",
+					"Disabled linters: unused",
+					"Formatters: gofumpt, goimports",
+					"Maximum function length: 60 lines (funlen)",
+				}},
+			},
+		},
+		{
+			name: "eslint and prettier",
+			files: map[string]string{
+				".eslintrc.json": `{"extends": ["eslint:recommended", "prettier"], "rules": {"no-console": "warn", "eqeqeq": ["error", "always"], "no-unused-vars": "off", "semi": 2}}`,
+				".prettierrc":    "semi: false\nsingleQuote: true\nprintWidth: 100\noverrides:\n  - files: '*.md'\n",
+			},
+			conventions: []Convention{
+				{Tool: "ESLint", Source: ".eslintrc.json", Rules: []string{
+					"Extends: eslint:recommended, prettier",
+					"Rules: eqeqeq (error), no-console (warn), semi (error)",
+				}},
+				{Tool: "Prettier", Source: ".prettierrc", Rules: []string{
+					"printWidth: 100",
+					"semi: false",
+					"singleQuote: true",
+				}},
+			},
+		},
+		{
+			name: "javascript eslint config",
+			files: map[string]string{
+				"eslint.config.js": "export default [{ rules: { semi: 'error' } }]\n",
+			},
+			conventions: []Convention{
+				{Tool: "ESLint", Source: "eslint.config.js", Rules: []string{"Configured in JavaScript; run the linter to see the rules"}},
+			},
+		},
+		{
+			name: "ruff in pyproject",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"svc\"\nversion = \"1.0\"\n\n[tool.ruff]\nline-length = 88\ntarget-version = \"py311\"\n\n[tool.ruff.lint]\nselect = [\n  \"E\",  # pycodestyle\n  \"F\",\n  \"I\",\n]\nignore = [\"E501\"]\n\n[tool.ruff.lint.per-file-ignores]\n\"tests/*\" = [\"S101\"]\n\n[tool.ruff.format]\nquote-style = \"single\"\n",
+			},
+			conventions: []Convention{
+				{Tool: "Ruff", Source: "pyproject.toml", Rules: []string{
+					"Maximum line length: 88",
+					"target-version: py311",
+					"Selected rules: E, F, I",
+					"Ignored rules: E501",
+					"format.quote-style: single",
+				}},
+			},
+		},
+		{
+			name: "ruff.toml takes precedence over pyproject",
+			files: map[string]string{
+				"pyproject.toml": "[tool.ruff]\nline-length = 88\n",
+				"ruff.toml":      "line-length = 100\n\n[lint]\nselect = [\"ALL\"]\n",
+			},
+			conventions: []Convention{
+				{Tool: "Ruff", Source: "ruff.toml", Rules: []string{
+					"Maximum line length: 100",
+					"Selected rules: ALL",
+				}},
+			},
+		},
+		{
+			name: "rustfmt",
+			files: map[string]string{
+				"Cargo.toml":   "[package]\nname = \"tool\"\n",
+				"rustfmt.toml": "edition = \"2021\"\nmax_width = 100 # wider than default\nimports_granularity = \"Crate\"\n",
+			},
+			conventions: []Convention{
+				{Tool: "rustfmt", Source: "rustfmt.toml", Rules: []string{
+					"edition: 2021",
+					"max_width: 100",
+					"imports_granularity: Crate",
+				}},
+			},
+		},
+		{
+			name: "pyproject without ruff",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"svc\"\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := os.MkdirTemp("", "project-conventions-test-*")
+			if err != nil {
+				t.Fatalf("Failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(root)
+
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			facts, err := Scan(root)
+			if err != nil {
+				t.Fatalf("Scan failed: %v", err)
+			}
+			if !reflect.DeepEqual(facts.Conventions, tt.conventions) {
+				t.Errorf("Expected conventions %+v\ngot %+v", tt.conventions, facts.Conventions)
+			}
+		})
+	}
+}
+
+func TestDiscoverConventionsInvalid(t *testing.T) {
+	root, err := os.MkdirTemp("", "project-conventions-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	if err := os.WriteFile(filepath.Join(root, ".golangci.yml"), []byte("linters: [unclosed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	facts, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Expected an invalid .golangci.yml not to fail the scan, got %v", err)
+	}
+	expected := []Convention{{Tool: "golangci-lint", Source: ".golangci.yml", Rules: []string{"Could not be read; check .golangci.yml directly"}}}
+	if !reflect.DeepEqual(facts.Conventions, expected) {
+		t.Errorf("Expected conventions %+v\ngot %+v", expected, facts.Conventions)
+	}
+}
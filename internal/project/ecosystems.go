@@ -0,0 +1,348 @@
+package project
+
+import (
+	"bufio"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// framework maps a dependency, as it appears in a manifest, to a name
+type framework struct {
+	dependency string
+	name       string
+}
+
+// addFrameworks records every framework whose dependency matches
+func (f *Facts) addFrameworks(known []framework, hasDependency func(string) bool) {
+	for _, fw := range known {
+		if hasDependency(fw.dependency) {
+			f.addFramework(fw.name)
+		}
+	}
+}
+
+var goFrameworks = []framework{
+	{"github.com/gin-gonic/gin", "Gin"},
+	{"github.com/labstack/echo", "Echo"},
+	{"github.com/gofiber/fiber", "Fiber"},
+	{"github.com/go-chi/chi", "Chi"},
+	{"github.com/gorilla/mux", "Gorilla Mux"},
+	{"google.golang.org/grpc", "gRPC"},
+	{"gorm.io/gorm", "GORM"},
+	{"github.com/spf13/cobra", "Cobra"},
+	{"github.com/urfave/cli", "urfave/cli"},
+	{"github.com/stretchr/testify", "Testify"},
+}
+
+func detectGo(root string, f *Facts) error {
+	gomod, err := readFile(root, "go.mod")
+	if err != nil {
+		return err
+	}
+
+	f.Language = "Go"
+	f.PackageManager = "Go modules"
+	f.BuildCommand = "go build ./..."
+	f.TestCommand = "go test ./..."
+	f.LintCommand = "go vet ./..."
+	if anyExists(root, ".golangci.yml", ".golangci.yaml", ".golangci.toml", ".golangci.json") {
+		f.LintCommand = "golangci-lint run"
+	}
+
+	var requires []string
+	scanner := bufio.NewScanner(strings.NewReader(gomod))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) >= 2 && fields[0] == "module":
+			f.ModulePath = strings.Trim(fields[1], `"`)
+		case len(fields) >= 2 && fields[0] == "require" && fields[1] != "(":
+			requires = append(requires, fields[1])
+		case len(fields) >= 2 && strings.Contains(fields[0], "."):
+			// A line inside a require block
+			requires = append(requires, fields[0])
+		}
+	}
+
+	f.addFrameworks(goFrameworks, func(dep string) bool {
+		for _, req := range requires {
+			if req == dep || strings.HasPrefix(req, dep+"/") {
+				return true
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+var rustFrameworks = []framework{
+	{"actix-web", "Actix Web"},
+	{"axum", "Axum"},
+	{"rocket", "Rocket"},
+	{"tokio", "Tokio"},
+	{"clap", "Clap"},
+}
+
+func detectRust(root string, f *Facts) error {
+	cargo, err := readFile(root, "Cargo.toml")
+	if err != nil {
+		return err
+	}
+
+	f.Language = "Rust"
+	f.PackageManager = "Cargo"
+	f.BuildCommand = "cargo build"
+	f.TestCommand = "cargo test"
+	f.LintCommand = "cargo clippy"
+
+	manifest := parseTOML(cargo)
+	if name, ok := manifest.value("package", "name"); ok && f.ModulePath == "" {
+		f.ModulePath = tomlScalar(name)
+	}
+	deps := make(map[string]bool)
+	for _, e := range manifest.Entries {
+		if dep, ok := cargoDependency(e); ok {
+			deps[dep] = true
+		}
+	}
+	f.addFrameworks(rustFrameworks, func(dep string) bool { return deps[dep] })
+	return nil
+}
+
+// cargoDependency returns the crate an entry of Cargo.toml declares: a key
+// of a [dependencies] table, such as tokio = "1" or tokio.version = "1", or
+// the name of a [dependencies.tokio] table
+func cargoDependency(e tomlEntry) (string, bool) {
+	if strings.HasSuffix(e.Section, "dependencies") {
+		name, _, _ := strings.Cut(e.Key, ".")
+		return name, true
+	}
+	if i := strings.LastIndex(e.Section, "."); i >= 0 && strings.HasSuffix(e.Section[:i], "dependencies") {
+		return e.Section[i+1:], true
+	}
+	return "", false
+}
+
+var nodeFrameworks = []framework{
+	{"next", "Next.js"},
+	{"react", "React"},
+	{"vue", "Vue"},
+	{"@angular/core", "Angular"},
+	{"svelte", "Svelte"},
+	{"@nestjs/core", "NestJS"},
+	{"express", "Express"},
+	{"fastify", "Fastify"},
+	{"jest", "Jest"},
+	{"vitest", "Vitest"},
+}
+
+// packageJSON is the part of package.json the scanner reads
+type packageJSON struct {
+	Name            string            `json:"name"`
+	PackageManager  string            `json:"packageManager"`
+	Scripts         map[string]string `json:"scripts"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+}
+
+func detectNode(root string, f *Facts) error {
+	data, err := readFile(root, "package.json")
+	if err != nil {
+		return err
+	}
+	var pkg packageJSON
+	if err := json.Unmarshal([]byte(data), &pkg); err != nil {
+		// It still marks a Node project; what it would have said stays
+		// unknown
+		pkg = packageJSON{}
+	}
+
+	f.Language = "JavaScript"
+	if anyExists(root, "tsconfig.json") || pkg.DevDependencies["typescript"] != "" || pkg.Dependencies["typescript"] != "" {
+		f.Language = "TypeScript"
+	}
+	f.ModulePath = pkg.Name
+
+	// Commands come from the scripts the project defines, see discoverScripts
+	f.PackageManager = nodePackageManager(root, pkg)
+
+	f.addFrameworks(nodeFrameworks, func(dep string) bool {
+		return pkg.Dependencies[dep] != "" || pkg.DevDependencies[dep] != ""
+	})
+	return nil
+}
+
+// nodePackageManager returns the package manager a Node project uses
+func nodePackageManager(root string, pkg packageJSON) string {
+	switch {
+	case pkg.PackageManager != "":
+		pm, _, _ := strings.Cut(pkg.PackageManager, "@")
+		return pm
+	case anyExists(root, "pnpm-lock.yaml"):
+		return "pnpm"
+	case anyExists(root, "yarn.lock"):
+		return "yarn"
+	case anyExists(root, "bun.lockb", "bun.lock"):
+		return "bun"
+	}
+	return "npm"
+}
+
+var pythonFrameworks = []framework{
+	{"django", "Django"},
+	{"flask", "Flask"},
+	{"fastapi", "FastAPI"},
+	{"pydantic", "Pydantic"},
+	{"sqlalchemy", "SQLAlchemy"},
+	{"pytest", "pytest"},
+}
+
+// pythonRequirement matches the distribution name at the start of a
+// requirement such as "Django>=4.2" or "fastapi[all]"
+var pythonRequirement = regexp.MustCompile(`^["']?([A-Za-z0-9_.-]+)`)
+
+func detectPython(root string, f *Facts) error {
+	var manifests []string
+	for _, name := range []string{"pyproject.toml", "Pipfile", "requirements.txt", "requirements-dev.txt", "setup.py"} {
+		content, err := readFile(root, name)
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, content)
+	}
+	pyproject, pipfile := parseTOML(manifests[0]), parseTOML(manifests[1])
+
+	f.Language = "Python"
+	switch {
+	case anyExists(root, "poetry.lock") || pyproject.hasTable("tool.poetry"):
+		f.PackageManager = "Poetry"
+	case anyExists(root, "uv.lock"):
+		f.PackageManager = "uv"
+	case anyExists(root, "Pipfile"):
+		f.PackageManager = "Pipenv"
+	default:
+		f.PackageManager = "pip"
+	}
+
+	f.TestCommand = "pytest"
+	if anyExists(root, "ruff.toml", ".ruff.toml") || pyproject.hasTable("tool.ruff") {
+		f.LintCommand = "ruff check ."
+	} else if anyExists(root, ".flake8") {
+		f.LintCommand = "flake8"
+	}
+	if manifests[0] != "" {
+		f.BuildCommand = "python -m build"
+	}
+
+	for _, section := range []string{"project", "tool.poetry"} {
+		if name, ok := pyproject.value(section, "name"); ok && f.ModulePath == "" {
+			f.ModulePath = tomlScalar(name)
+		}
+	}
+
+	deps := make(map[string]bool)
+	for _, dep := range append(pythonDependencies(pyproject), pythonDependencies(pipfile)...) {
+		deps[strings.ToLower(dep)] = true
+	}
+	// requirements files and setup.py are not TOML: any requirement-like
+	// word counts
+	for _, content := range manifests[2:] {
+		for _, line := range strings.Split(content, "\n") {
+			for _, field := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == '[' || r == ' ' || r == '=' }) {
+				if m := pythonRequirement.FindStringSubmatch(field); m != nil {
+					deps[strings.ToLower(m[1])] = true
+				}
+			}
+		}
+	}
+	f.addFrameworks(pythonFrameworks, func(dep string) bool { return deps[dep] })
+	return nil
+}
+
+// pythonDependencies returns the distributions a pyproject.toml or Pipfile
+// declares: the requirement lists of [project] and [dependency-groups], and
+// the keys of the Poetry and Pipenv dependency tables
+func pythonDependencies(t tomlFile) []string {
+	var deps []string
+	for _, e := range t.Entries {
+		switch {
+		case e.Section == "project" && e.Key == "dependencies",
+			e.Section == "project.optional-dependencies",
+			e.Section == "dependency-groups":
+			for _, requirement := range tomlList(e.Value) {
+				if m := pythonRequirement.FindStringSubmatch(requirement); m != nil {
+					deps = append(deps, m[1])
+				}
+			}
+		case e.Section == "packages" || e.Section == "dev-packages",
+			strings.HasPrefix(e.Section, "tool.poetry") && strings.HasSuffix(e.Section, "dependencies"):
+			deps = append(deps, e.Key)
+		}
+	}
+	return deps
+}
+
+func detectJava(root string, f *Facts) error {
+	pom, err := readFile(root, "pom.xml")
+	if err != nil {
+		return err
+	}
+	gradle, err := readFile(root, "build.gradle")
+	if err != nil {
+		return err
+	}
+	gradleKts, err := readFile(root, "build.gradle.kts")
+	if err != nil {
+		return err
+	}
+
+	f.Language = "Java"
+	if gradleKts != "" && strings.Contains(gradleKts, "kotlin(") {
+		f.Language = "Kotlin"
+	}
+
+	if pom != "" {
+		f.PackageManager = "Maven"
+		f.BuildCommand = "mvn package"
+		f.TestCommand = "mvn test"
+	} else {
+		wrapper := "gradle"
+		if anyExists(root, "gradlew") {
+			wrapper = "./gradlew"
+		}
+		f.PackageManager = "Gradle"
+		f.BuildCommand = wrapper + " build"
+		f.TestCommand = wrapper + " test"
+	}
+
+	all := pom + gradle + gradleKts
+	if strings.Contains(all, "spring-boot") {
+		f.addFramework("Spring Boot")
+	}
+	if strings.Contains(all, "junit") {
+		f.addFramework("JUnit")
+	}
+	return nil
+}
+
+func detectRuby(root string, f *Facts) error {
+	gemfile, err := readFile(root, "Gemfile")
+	if err != nil {
+		return err
+	}
+
+	f.Language = "Ruby"
+	f.PackageManager = "Bundler"
+	f.TestCommand = "bundle exec rake test"
+	if anyExists(root, "spec") {
+		f.TestCommand = "bundle exec rspec"
+	}
+	if strings.Contains(gemfile, "rubocop") {
+		f.LintCommand = "bundle exec rubocop"
+	}
+	if strings.Contains(gemfile, `"rails"`) || strings.Contains(gemfile, `'rails'`) {
+		f.addFramework("Rails")
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SubProject is a project nested in a monorepo, such as a Go module or a
+// package.json workspace
+type SubProject struct {
+	Dir   string // slash path relative to the repository root
+	Facts Facts
+}
+
+// skipDirs are never searched for nested modules
+var skipDirs = map[string]bool{
+	".git": true, "node_modules": true, "vendor": true, "testdata": true,
+	"target": true, "dist": true, "build": true, ".venv": true, "venv": true,
+}
+
+// FindSubProjects detects the sub-projects of a monorepo at root: nested Go
+// modules and the members of package.json, pnpm and Cargo workspaces. The
+// root project itself is not included. Directories whose language cannot
+// be detected are left out.
+func FindSubProjects(root string) ([]SubProject, error) {
+	dirs := make(map[string]bool)
+
+	modules, err := nestedGoModules(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range modules {
+		dirs[dir] = true
+	}
+
+	for _, find := range []func(string) ([]string, error){nodeWorkspaces, pnpmWorkspaces, cargoWorkspaces} {
+		patterns, err := find(root)
+		if err != nil {
+			return nil, err
+		}
+		members, err := expandWorkspaces(root, patterns)
+		if err != nil {
+			return nil, err
+		}
+		for _, dir := range members {
+			dirs[dir] = true
+		}
+	}
+
+	var subs []SubProject
+	for dir := range dirs {
+		facts, err := Scan(filepath.Join(root, filepath.FromSlash(dir)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+		}
+		if facts.Language == "" {
+			continue
+		}
+		subs = append(subs, SubProject{Dir: dir, Facts: facts})
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].Dir < subs[j].Dir })
+	return subs, nil
+}
+
+// nestedGoModules returns every directory below root holding a go.mod
+func nestedGoModules(root string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p == root {
+				return nil
+			}
+			// Nested repositories are projects of their own, not sub-projects
+			if skipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".") || anyExists(p, ".git") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "go.mod" {
+			return nil
+		}
+		dir, err := filepath.Rel(root, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		if dir != "." {
+			dirs = append(dirs, filepath.ToSlash(dir))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for Go modules: %w", err)
+	}
+	return dirs, nil
+}
+
+// nodeWorkspaces returns the workspace patterns of the root package.json,
+// given either as an array or as {"packages": [...]}
+func nodeWorkspaces(root string) ([]string, error) {
+	content, err := readFile(root, "package.json")
+	if err != nil || content == "" {
+		return nil, err
+	}
+	var pkg struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err := json.Unmarshal([]byte(content), &pkg); err != nil {
+		// A manifest that does not parse declares no workspaces
+		return nil, nil
+	}
+	if len(pkg.Workspaces) == 0 {
+		return nil, nil
+	}
+
+	var patterns []string
+	if err := json.Unmarshal(pkg.Workspaces, &patterns); err == nil {
+		return patterns, nil
+	}
+	var object struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(pkg.Workspaces, &object); err != nil {
+		return nil, nil
+	}
+	return object.Packages, nil
+}
+
+// pnpmWorkspaces returns the package patterns of pnpm-workspace.yaml
+func pnpmWorkspaces(root string) ([]string, error) {
+	content, err := readFile(root, "pnpm-workspace.yaml")
+	if err != nil || content == "" {
+		return nil, err
+	}
+	var workspace struct {
+		Packages []string `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &workspace); err != nil {
+		return nil, nil
+	}
+	return workspace.Packages, nil
+}
+
+// cargoWorkspaces returns the members of a Cargo workspace
+func cargoWorkspaces(root string) ([]string, error) {
+	content, err := readFile(root, "Cargo.toml")
+	if err != nil || content == "" {
+		return nil, err
+	}
+
+	members, _ := parseTOML(content).value("workspace", "members")
+	return tomlList(members), nil
+}
+
+// expandWorkspaces resolves workspace patterns such as "packages/*" or
+// "apps/**" to the directories beneath root they match. Patterns starting
+// with "!" exclude directories.
+func expandWorkspaces(root string, patterns []string) ([]string, error) {
+	included := make(map[string]bool)
+	var excluded []string
+	for _, pattern := range patterns {
+		if rest, ok := strings.CutPrefix(pattern, "!"); ok {
+			excluded = append(excluded, workspacePattern(rest))
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(workspacePattern(pattern))))
+		if err != nil {
+			return nil, fmt.Errorf("invalid workspace pattern %q: %w", pattern, err)
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || !info.IsDir() {
+				continue
+			}
+			rel, err := filepath.Rel(root, match)
+			if err != nil {
+				return nil, err
+			}
+			// Patterns such as "../shared" must not reach outside the
+			// repository
+			if rel != "." && filepath.IsLocal(rel) {
+				included[filepath.ToSlash(rel)] = true
+			}
+		}
+	}
+
+	var dirs []string
+	for dir := range included {
+		skip := false
+		for _, pattern := range excluded {
+			if ok, _ := path.Match(pattern, dir); ok {
+				skip = true
+			}
+		}
+		if !skip {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// workspacePattern normalizes a workspace pattern for path matching:
+// "./apps/**" becomes "apps/*", since workspaces are one level deep in
+// practice and filepath.Glob has no recursive wildcard
+func workspacePattern(pattern string) string {
+	pattern = strings.TrimPrefix(strings.TrimSuffix(pattern, "/"), "./")
+	return strings.ReplaceAll(pattern, "**", "*")
+}
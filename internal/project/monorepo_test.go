@@ -0,0 +1,136 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindSubProjects(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected map[string]string // directory to language
+	}{
+		{
+			name:     "single project",
+			files:    map[string]string{"go.mod": "module example.com/app\n"},
+			expected: map[string]string{},
+		},
+		{
+			name: "go services and npm workspaces",
+			files: map[string]string{
+				"package.json":                       `{"private": true, "workspaces": ["apps/*", "!apps/legacy"]}`,
+				"apps/web/package.json":              `{"name": "web", "devDependencies": {"typescript": "5"}}`,
+				"apps/legacy/package.json":           `{"name": "legacy"}`,
+				"services/api/go.mod":                "module example.com/api\n",
+				"services/worker/go.mod":             "module example.com/worker\n",
+				"services/api/node_modules/x/go.mod": "module example.com/x\n",
+				"docs/README.md":                     "# Docs\n",
+			},
+			expected: map[string]string{
+				"apps/web":        "TypeScript",
+				"services/api":    "Go",
+				"services/worker": "Go",
+			},
+		},
+		{
+			name: "yarn workspaces object and pnpm workspace",
+			files: map[string]string{
+				"package.json":             `{"workspaces": {"packages": ["packages/*"]}}`,
+				"pnpm-workspace.yaml":      "packages:\n  - 'tools/**'\n",
+				"packages/ui/package.json": `{"name": "ui"}`,
+				"tools/cli/package.json":   `{"name": "cli"}`,
+				"tools/README.md":          "# Tools\n",
+			},
+			expected: map[string]string{
+				"packages/ui": "JavaScript",
+				"tools/cli":   "JavaScript",
+			},
+		},
+		{
+			name: "cargo workspace",
+			files: map[string]string{
+				"Cargo.toml":             "[workspace]\nmembers = [\n  \"crates/*\", # libraries\n  \"cli\",\n]\n",
+				"crates/core/Cargo.toml": "[package]\nname = \"core\"\n",
+				"cli/Cargo.toml":         "[package]\nname = \"cli\"\n",
+			},
+			expected: map[string]string{
+				"cli":         "Rust",
+				"crates/core": "Rust",
+			},
+		},
+		{
+			name: "nested repository is not a sub-project",
+			files: map[string]string{
+				"go.mod":               "module example.com/app\n",
+				"third_party/x/.git":   "gitdir: ../../.git/modules/x\n",
+				"third_party/x/go.mod": "module example.com/x\n",
+			},
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := os.MkdirTemp("", "project-monorepo-test-*")
+			if err != nil {
+				t.Fatalf("Failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(root)
+
+			for name, content := range tt.files {
+				path := filepath.Join(root, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			subs, err := FindSubProjects(root)
+			if err != nil {
+				t.Fatalf("FindSubProjects failed: %v", err)
+			}
+			got := map[string]string{}
+			for _, sub := range subs {
+				got[sub.Dir] = sub.Facts.Language
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFindSubProjectsOutsideRoot(t *testing.T) {
+	dir, err := os.MkdirTemp("", "project-monorepo-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"repo/package.json":   `{"private": true, "workspaces": ["../shared", "../*"]}`,
+		"shared/package.json": `{"name": "shared"}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	subs, err := FindSubProjects(filepath.Join(dir, "repo"))
+	if err != nil {
+		t.Fatalf("FindSubProjects failed: %v", err)
+	}
+	if len(subs) != 0 {
+		t.Errorf("Expected workspaces outside the repository to be ignored, got %+v", subs)
+	}
+}
@@ -0,0 +1,126 @@
+// Package project detects facts about a repository, such as its language,
+// frameworks and build commands, so prompts can state them instead of asking
+// the agent to rediscover them
+package project
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Facts are what a scan found out about a project. Empty fields are unknown.
+type Facts struct {
+	Language       string
+	Frameworks     []string
+	ModulePath     string // Go module path, or package name for other ecosystems
+	PackageManager string
+	TestCommand    string
+	BuildCommand   string
+	LintCommand    string
+	SourceDirs     []string
+
+	// Commands are the build, test and lint commands the project defines in
+	// its Makefile, package.json scripts and other task runners
+	Commands []Command
+
+	// Conventions are the rules its linter and formatter configurations
+	// enforce
+	Conventions []Convention
+}
+
+// ecosystem detects one kind of project from the files at its root
+type ecosystem struct {
+	// markers are files whose presence identifies the ecosystem
+	markers []string
+	// detect fills in facts; it is only called when a marker exists
+	detect func(root string, f *Facts) error
+}
+
+// ecosystems in order of precedence: the first one found provides the
+// language and commands, e.g. a Go service with a package.json for its
+// frontend tooling is a Go project
+var ecosystems = []ecosystem{
+	{markers: []string{"go.mod"}, detect: detectGo},
+	{markers: []string{"Cargo.toml"}, detect: detectRust},
+	{markers: []string{"package.json"}, detect: detectNode},
+	{markers: []string{"pyproject.toml", "setup.py", "requirements.txt", "Pipfile"}, detect: detectPython},
+	{markers: []string{"pom.xml", "build.gradle", "build.gradle.kts"}, detect: detectJava},
+	{markers: []string{"Gemfile"}, detect: detectRuby},
+}
+
+// sourceDirCandidates are conventional source directories, listed in the
+// order they are reported
+var sourceDirCandidates = []string{"cmd", "internal", "pkg", "src", "lib", "app", "api", "server", "client", "web", "tests", "test", "spec"}
+
+// Scan inspects the project at root. Frameworks found by later ecosystems
+// are added to those of the primary one.
+func Scan(root string) (Facts, error) {
+	var facts Facts
+	for _, eco := range ecosystems {
+		if !anyExists(root, eco.markers...) {
+			continue
+		}
+
+		if facts.Language == "" {
+			if err := eco.detect(root, &facts); err != nil {
+				return Facts{}, err
+			}
+			continue
+		}
+
+		// Secondary ecosystem: keep only its frameworks
+		var secondary Facts
+		if err := eco.detect(root, &secondary); err != nil {
+			return Facts{}, err
+		}
+		for _, fw := range secondary.Frameworks {
+			facts.addFramework(fw)
+		}
+	}
+
+	discoverCommands(root, &facts)
+	discoverConventions(root, &facts)
+
+	for _, dir := range sourceDirCandidates {
+		if info, err := os.Stat(filepath.Join(root, dir)); err == nil && info.IsDir() {
+			facts.SourceDirs = append(facts.SourceDirs, dir)
+		}
+	}
+	return facts, nil
+}
+
+// addFramework records a framework once
+func (f *Facts) addFramework(name string) {
+	for _, existing := range f.Frameworks {
+		if existing == name {
+			return
+		}
+	}
+	f.Frameworks = append(f.Frameworks, name)
+}
+
+// readFile returns the content of a file beneath root, or "" if it does not
+// exist
+func readFile(root, name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(root, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return string(data), nil
+}
+
+// anyExists reports whether any of the named files exists beneath root
+func anyExists(root string, names ...string) bool {
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,241 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected Facts
+	}{
+		{
+			name:     "empty project",
+			files:    map[string]string{"README.md": "# Hello\n"},
+			expected: Facts{},
+		},
+		{
+			name: "go module",
+			files: map[string]string{
+				"go.mod":          "module github.com/acme/api\n\ngo 1.22\n\nrequire (\n\tgithub.com/gin-gonic/gin v1.9.1\n\tgorm.io/gorm v1.25.0 // indirect\n)\n\nrequire github.com/spf13/cobra v1.8.0\n",
+				".golangci.yml":   "linters:\n  enable: [errcheck]\n",
+				"cmd/api/main.go": "package main\n",
+				"internal/x/x.go": "package x\n",
+				"docs/index.md":   "# Docs\n",
+			},
+			expected: Facts{
+				Language:       "Go",
+				Frameworks:     []string{"Gin", "GORM", "Cobra"},
+				ModulePath:     "github.com/acme/api",
+				PackageManager: "Go modules",
+				TestCommand:    "go test ./...",
+				BuildCommand:   "go build ./...",
+				LintCommand:    "golangci-lint run",
+				SourceDirs:     []string{"cmd", "internal"},
+				Conventions: []Convention{
+					{Tool: "golangci-lint", Source: ".golangci.yml", Rules: []string{"Enabled linters: errcheck"}},
+				},
+			},
+		},
+		{
+			name: "typescript with pnpm",
+			files: map[string]string{
+				"package.json":   `{"name": "@acme/web", "scripts": {"test": "vitest", "build": "next build"}, "dependencies": {"next": "14", "react": "18"}, "devDependencies": {"typescript": "5", "vitest": "1"}}`,
+				"pnpm-lock.yaml": "lockfileVersion: 6\n",
+				"src/index.ts":   "export {}\n",
+			},
+			expected: Facts{
+				Language:       "TypeScript",
+				Frameworks:     []string{"Next.js", "React", "Vitest"},
+				ModulePath:     "@acme/web",
+				PackageManager: "pnpm",
+				TestCommand:    "pnpm test",
+				BuildCommand:   "pnpm run build",
+				SourceDirs:     []string{"src"},
+				Commands: []Command{
+					{Kind: KindBuild, Run: "pnpm run build", Source: "package.json"},
+					{Kind: KindTest, Run: "pnpm test", Source: "package.json"},
+				},
+			},
+		},
+		{
+			name: "python with poetry and ruff",
+			files: map[string]string{
+				"pyproject.toml":  "[tool.poetry]\nname = \"billing\"\n\n[tool.poetry.dependencies]\npython = \"^3.11\"\nfastapi = \"^0.110\"\n\n[tool.ruff]\nline-length = 100\n",
+				"poetry.lock":     "",
+				"tests/test_x.py": "",
+			},
+			expected: Facts{
+				Language:       "Python",
+				Frameworks:     []string{"FastAPI"},
+				ModulePath:     "billing",
+				PackageManager: "Poetry",
+				TestCommand:    "pytest",
+				BuildCommand:   "python -m build",
+				LintCommand:    "ruff check .",
+				SourceDirs:     []string{"tests"},
+				Commands: []Command{
+					{Kind: KindLint, Run: "ruff check .", Source: "pyproject.toml"},
+				},
+				Conventions: []Convention{
+					{Tool: "Ruff", Source: "pyproject.toml", Rules: []string{"Maximum line length: 100"}},
+				},
+			},
+		},
+		{
+			name: "rust crate",
+			files: map[string]string{
+				"Cargo.toml": "[package]\nname = \"acme-cli\"\nversion = \"0.1.0\"\n\n[dependencies]\nclap = { version = \"4\" }\ntokio = \"1\"\n",
+			},
+			expected: Facts{
+				Language:       "Rust",
+				Frameworks:     []string{"Tokio", "Clap"},
+				ModulePath:     "acme-cli",
+				PackageManager: "Cargo",
+				TestCommand:    "cargo test",
+				BuildCommand:   "cargo build",
+				LintCommand:    "cargo clippy",
+			},
+		},
+		{
+			name: "rust crate with dependency tables",
+			files: map[string]string{
+				"Cargo.toml": "[package]\nname = \"api\" # the service\n\n[dependencies.axum]\nversion = \"0.7\"\n\n[dependencies]\ntokio.workspace = true\n# rocket = \"0.5\"\n",
+			},
+			expected: Facts{
+				Language:       "Rust",
+				Frameworks:     []string{"Axum", "Tokio"},
+				ModulePath:     "api",
+				PackageManager: "Cargo",
+				TestCommand:    "cargo test",
+				BuildCommand:   "cargo build",
+				LintCommand:    "cargo clippy",
+			},
+		},
+		{
+			name: "python with project dependencies and pipenv",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"shop\"\ndependencies = [\n  \"Django>=4.2,<5\",  # web\n  \"pydantic\",\n]\n",
+				"Pipfile":        "[packages]\nflask = \"*\"\n\n[dev-packages]\npytest = \"*\"\n",
+			},
+			expected: Facts{
+				Language:       "Python",
+				Frameworks:     []string{"Django", "Flask", "Pydantic", "pytest"},
+				ModulePath:     "shop",
+				PackageManager: "Pipenv",
+				TestCommand:    "pytest",
+				BuildCommand:   "python -m build",
+			},
+		},
+		{
+			name: "go service with frontend tooling",
+			files: map[string]string{
+				"go.mod":       "module example.com/svc\n\ngo 1.22\n",
+				"package.json": `{"devDependencies": {"react": "18"}}`,
+			},
+			expected: Facts{
+				Language:       "Go",
+				Frameworks:     []string{"React"},
+				ModulePath:     "example.com/svc",
+				PackageManager: "Go modules",
+				TestCommand:    "go test ./...",
+				BuildCommand:   "go build ./...",
+				LintCommand:    "go vet ./...",
+			},
+		},
+		{
+			name: "spring boot with gradle wrapper",
+			files: map[string]string{
+				"build.gradle": "plugins { id 'org.springframework.boot' version '3.2.0' }\ndependencies { implementation 'org.springframework.boot:spring-boot-starter-web' }\n",
+				"gradlew":      "#!/bin/sh\n",
+			},
+			expected: Facts{
+				Language:       "Java",
+				Frameworks:     []string{"Spring Boot"},
+				PackageManager: "Gradle",
+				TestCommand:    "./gradlew test",
+				BuildCommand:   "./gradlew build",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := os.MkdirTemp("", "project-scan-test-*")
+			if err != nil {
+				t.Fatalf("Failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(root)
+
+			for name, content := range tt.files {
+				path := filepath.Join(root, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			facts, err := Scan(root)
+			if err != nil {
+				t.Fatalf("Scan failed: %v", err)
+			}
+			if !reflect.DeepEqual(facts, tt.expected) {
+				t.Errorf("Expected %+v\ngot      %+v", tt.expected, facts)
+			}
+		})
+	}
+}
+
+func TestScanInvalidPackageJSON(t *testing.T) {
+	root, err := os.MkdirTemp("", "project-scan-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		"package.json":        `{"name": "web", "workspaces": [`,
+		"pnpm-lock.yaml":      "",
+		"tsconfig.json":       "{}",
+		"pnpm-workspace.yaml": "packages: [unclosed\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The markers still tell what kind of project it is
+	facts, err := Scan(root)
+	if err != nil {
+		t.Fatalf("Expected an invalid package.json not to fail the scan, got %v", err)
+	}
+	if facts.Language != "TypeScript" || facts.PackageManager != "pnpm" || facts.ModulePath != "" {
+		t.Errorf("Unexpected facts %+v", facts)
+	}
+	if subs, err := FindSubProjects(root); err != nil || len(subs) != 0 {
+		t.Errorf("Expected no sub-projects, got %+v, %v", subs, err)
+	}
+}
+
+func TestScanUnreadableManifest(t *testing.T) {
+	root, err := os.MkdirTemp("", "project-scan-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	// A directory where the manifest should be cannot be read
+	if err := os.Mkdir(filepath.Join(root, "package.json"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Scan(root); err == nil {
+		t.Error("Expected an error for a package.json that cannot be read")
+	}
+}
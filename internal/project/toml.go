@@ -0,0 +1,127 @@
+package project
+
+import (
+	"strings"
+)
+
+// tomlFile is what parseTOML reads from a TOML file
+type tomlFile struct {
+	Tables  []string // dotted names of the [tables], in file order
+	Entries []tomlEntry
+}
+
+// tomlEntry is one key = value assignment of a TOML file
+type tomlEntry struct {
+	Section string // dotted table name, "" at the top level
+	Key     string
+	Value   string // raw value; arrays spanning lines are joined
+}
+
+// parseTOML reads the tables and key = value assignments of a TOML file. It
+// covers the manifests and configuration files the scanner reads, not the
+// whole TOML language: inline tables are returned as raw values.
+func parseTOML(content string) tomlFile {
+	var file tomlFile
+	var entries []tomlEntry
+	section := ""
+	var pending *tomlEntry
+	depth := 0
+
+	for _, line := range strings.Split(content, "\n") {
+		line = stripTOMLComment(line)
+		trimmed := strings.TrimSpace(line)
+
+		if pending != nil {
+			pending.Value += " " + trimmed
+			depth += strings.Count(trimmed, "[") - strings.Count(trimmed, "]")
+			if depth <= 0 {
+				entries = append(entries, *pending)
+				pending = nil
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") {
+			section = strings.Trim(trimmed, "[] ")
+			file.Tables = append(file.Tables, section)
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, "=")
+		if !ok {
+			continue
+		}
+		entry := tomlEntry{Section: section, Key: strings.Trim(strings.TrimSpace(key), `"`), Value: strings.TrimSpace(value)}
+		if strings.HasPrefix(entry.Value, "[") {
+			depth = strings.Count(entry.Value, "[") - strings.Count(entry.Value, "]")
+			if depth > 0 {
+				pending = &entry
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+	if pending != nil {
+		entries = append(entries, *pending)
+	}
+	file.Entries = entries
+	return file
+}
+
+// hasTable reports whether the file has the table name or one nested in it
+func (t tomlFile) hasTable(name string) bool {
+	for _, table := range t.Tables {
+		if table == name || strings.HasPrefix(table, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// value returns the raw value of key in section
+func (t tomlFile) value(section, key string) (string, bool) {
+	for _, e := range t.Entries {
+		if e.Section == section && e.Key == key {
+			return e.Value, true
+		}
+	}
+	return "", false
+}
+
+// stripTOMLComment removes a trailing # comment outside of strings
+func stripTOMLComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// tomlList returns the string items of a TOML array value
+func tomlList(value string) []string {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "[") {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(strings.Trim(value, "[] "), ",") {
+		item = strings.Trim(strings.TrimSpace(item), `"'`)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// tomlScalar returns a TOML value without its quotes
+func tomlScalar(value string) string {
+	return strings.Trim(strings.TrimSpace(value), `"'`)
+}
@@ -0,0 +1,159 @@
+package repomap
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignorePattern is one line of a .gitignore file
+type ignorePattern struct {
+	base    string // slash-separated directory of the .gitignore, "" at the root
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// Ignorer applies the .gitignore files of a repository. Patterns are added
+// directory by directory as the tree is walked, and later patterns take
+// precedence, as in git.
+type Ignorer struct {
+	patterns []ignorePattern
+}
+
+// AddFile reads the .gitignore in dir (slash-separated, relative to the
+// repository root), if there is one
+func (ig *Ignorer) AddFile(root, dir string) error {
+	content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(dir), ".gitignore"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ig.Add(dir, string(content))
+	return nil
+}
+
+// AddExclude reads the repository's own exclude file, .git/info/exclude,
+// whose patterns are relative to the root
+func (ig *Ignorer) AddExclude(root string) error {
+	content, err := os.ReadFile(filepath.Join(root, ".git", "info", "exclude"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ig.Add("", string(content))
+	return nil
+}
+
+// Add parses the content of the .gitignore in dir
+func (ig *Ignorer) Add(dir, content string) {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		p := ignorePattern{base: dir}
+		if strings.HasPrefix(line, "!") {
+			p.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			// \# and \! escape a literal first character
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		// A pattern with a slash is relative to the .gitignore; one without
+		// matches at any depth below it
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		expr := globToRegexp(line)
+		if !anchored {
+			expr = "(.*/)?" + expr
+		}
+		re, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			// git ignores patterns it cannot parse too
+			continue
+		}
+		p.re = re
+		ig.patterns = append(ig.patterns, p)
+	}
+}
+
+// Ignored reports whether the slash-separated path, relative to the
+// repository root, is ignored
+func (ig *Ignorer) Ignored(name string, isDir bool) bool {
+	ignored := false
+	for _, p := range ig.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		rel := name
+		if p.base != "" {
+			if !strings.HasPrefix(name, p.base+"/") {
+				continue
+			}
+			rel = strings.TrimPrefix(name, p.base+"/")
+		}
+		if p.re.MatchString(rel) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// globToRegexp translates a gitignore glob into a regular expression
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// parent returns the slash-separated parent of name, "" at the root
+func parent(name string) string {
+	if dir := path.Dir(name); dir != "." {
+		return dir
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package repomap
+
+import "testing"
+
+func TestIgnorer(t *testing.T) {
+	var ig Ignorer
+	ig.Add("", "# build output\n*.log\n/bin/\ndist\n!keep.log\ndocs/**/*.tmp\n\\#notes\n")
+	ig.Add("web", "/generated\ncache/\n")
+
+	tests := []struct {
+		name    string
+		isDir   bool
+		ignored bool
+	}{
+		{"app.log", false, true},
+		{"internal/x/app.log", false, true},
+		{"keep.log", false, false},
+		{"bin", true, true},
+		{"cmd/bin", true, false},
+		{"bin", false, false},
+		{"dist", true, true},
+		{"web/dist", true, true},
+		{"docs/a/b/c.tmp", false, true},
+		{"docs/c.tmp", false, true},
+		{"src/c.tmp", false, false},
+		{"#notes", false, true},
+		{"web/generated", true, true},
+		{"generated", true, false},
+		{"web/src/generated", true, false},
+		{"web/src/cache", true, true},
+		{"cache", true, false},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := ig.Ignored(tt.name, tt.isDir); got != tt.ignored {
+			t.Errorf("Ignored(%q, %v) = %v, expected %v", tt.name, tt.isDir, got, tt.ignored)
+		}
+	}
+}
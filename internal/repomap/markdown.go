@@ -0,0 +1,114 @@
+package repomap
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultBudget is the default size limit of a rendered map in bytes,
+// roughly 3,000 tokens
+const DefaultBudget = 12000
+
+// maxDepth is the deepest tree rendered when the budget allows it
+const maxDepth = 4
+
+// maxChildren is the most entries listed per directory; the rest are counted
+const maxChildren = 20
+
+// Markdown renders the map as a document of its own within budget bytes.
+// The tree is made shallower and the package list shorter until it fits.
+func (m *Map) Markdown(budget int) string {
+	return m.fit(budget, "# Repository Map\n\n", "##")
+}
+
+// Section renders the map without a title and with third-level headings, to
+// be embedded in a prompt
+func (m *Map) Section(budget int) string {
+	return m.fit(budget, "", "###")
+}
+
+// fit renders the map within budget bytes
+func (m *Map) fit(budget int, title, heading string) string {
+	if budget <= 0 {
+		budget = DefaultBudget
+	}
+
+	var out string
+	for depth := maxDepth; depth >= 1; depth-- {
+		out = m.render(title, heading, depth, len(m.Packages))
+		if len(out) <= budget {
+			return out
+		}
+	}
+	for n := len(m.Packages) - 1; n >= 0; n-- {
+		out = m.render(title, heading, 1, n)
+		if len(out) <= budget {
+			return out
+		}
+	}
+
+	// Even the summary does not fit: cut it at the last whole line
+	const note = "\n_Truncated to fit the size budget._\n"
+	cut := budget - len(note)
+	if cut < 0 {
+		cut = 0
+	}
+	out = out[:cut]
+	if i := strings.LastIndex(out, "\n"); i >= 0 {
+		out = out[:i]
+	}
+	return out + note
+}
+
+// render writes the map with the tree down to depth and the first packages
+func (m *Map) render(title, heading string, depth, packages int) string {
+	var b strings.Builder
+	b.WriteString(title)
+
+	if len(m.EntryPoints) > 0 {
+		fmt.Fprintf(&b, "%s Entry Points\n\n", heading)
+		for _, e := range m.EntryPoints {
+			fmt.Fprintf(&b, "- `%s` (%s)\n", e.Path, e.Kind)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(m.Packages) > 0 {
+		fmt.Fprintf(&b, "%s Packages\n\n", heading)
+		for _, p := range m.Packages[:packages] {
+			if p.Summary == "" {
+				fmt.Fprintf(&b, "- `%s`\n", p.Path)
+			} else {
+				fmt.Fprintf(&b, "- `%s`: %s\n", p.Path, p.Summary)
+			}
+		}
+		if rest := len(m.Packages) - packages; rest > 0 {
+			fmt.Fprintf(&b, "- ... and %d more\n", rest)
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "%s Directory Tree\n\n```text\n", heading)
+	writeTree(&b, m.Tree, 0, depth)
+	b.WriteString("```\n")
+	return b.String()
+}
+
+// writeTree lists the children of n, indented by level, down to depth
+func writeTree(b *strings.Builder, n *Node, level, depth int) {
+	indent := strings.Repeat("  ", level)
+	for i, c := range n.Children {
+		if i == maxChildren {
+			fmt.Fprintf(b, "%s... %d more\n", indent, len(n.Children)-maxChildren)
+			return
+		}
+		if !c.Dir {
+			fmt.Fprintf(b, "%s%s\n", indent, c.Name)
+			continue
+		}
+		fmt.Fprintf(b, "%s%s/\n", indent, c.Name)
+		if level+1 < depth {
+			writeTree(b, c, level+1, depth)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+// Package repomap summarizes the layout of a repository for the analysis
+// stages of the workflows: a gitignore-aware directory tree, the entry
+// points, and the packages with a one-line summary of each.
+package repomap
+
+import (
+	"encoding/json"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Map is the layout of a repository
+type Map struct {
+	Tree        *Node
+	EntryPoints []EntryPoint
+	Packages    []Package
+}
+
+// Node is a file or directory of the tree
+type Node struct {
+	Name     string
+	Dir      bool
+	Children []*Node
+}
+
+// EntryPoint is where a program starts, e.g. a Go main package or a
+// package.json bin
+type EntryPoint struct {
+	Path string // slash-separated, relative to the repository root
+	Kind string
+}
+
+// Package is a Go package, a package manifest or a documented top-level
+// directory
+type Package struct {
+	Path    string // slash-separated directory, "." for the root
+	Summary string
+}
+
+// skipDirs are never mapped, whether or not they are gitignored
+var skipDirs = map[string]bool{
+	".git": true, "node_modules": true, "vendor": true, "__pycache__": true,
+	"target": true, "dist": true, ".venv": true, "venv": true,
+}
+
+// Generate maps the repository at root
+func Generate(root string) (*Map, error) {
+	m := &Map{Tree: &Node{Name: ".", Dir: true}}
+	nodes := map[string]*Node{"": m.Tree}
+	var ig Ignorer
+	goDirs := map[string][]string{}
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if name == "." {
+			if err := ig.AddExclude(root); err != nil {
+				return err
+			}
+			return ig.AddFile(root, "")
+		}
+
+		if d.IsDir() && (skipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".")) {
+			return filepath.SkipDir
+		}
+		if ig.Ignored(name, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		node := &Node{Name: d.Name(), Dir: d.IsDir()}
+		dir := parent(name)
+		nodes[dir].Children = append(nodes[dir].Children, node)
+		if d.IsDir() {
+			nodes[name] = node
+			return ig.AddFile(root, name)
+		}
+
+		if strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			goDirs[dir] = append(goDirs[dir], d.Name())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := map[string]string{}
+	for dir, files := range goDirs {
+		name, synopsis := goPackage(root, dir, files)
+		if name == "main" {
+			m.EntryPoints = append(m.EntryPoints, EntryPoint{Path: mainFile(dir, files), Kind: "Go main package"})
+		}
+		summaries[dir] = synopsis
+	}
+	for dir := range nodes {
+		if _, ok := summaries[dir]; ok {
+			continue
+		}
+		if summary, ok := manifestPackage(root, dir, m); ok {
+			summaries[dir] = summary
+		} else if !strings.Contains(dir, "/") && hasReadme(nodes[dir]) {
+			// The root and documented top-level directories, e.g. docs
+			summaries[dir] = ""
+		}
+	}
+	for dir, summary := range summaries {
+		if summary == "" {
+			summary = readmeSummary(root, dir)
+		}
+		p := dir
+		if p == "" {
+			p = "."
+		}
+		m.Packages = append(m.Packages, Package{Path: p, Summary: summary})
+	}
+
+	sort.Slice(m.Packages, func(i, j int) bool { return m.Packages[i].Path < m.Packages[j].Path })
+	sort.Slice(m.EntryPoints, func(i, j int) bool { return m.EntryPoints[i].Path < m.EntryPoints[j].Path })
+	sortTree(m.Tree)
+	return m, nil
+}
+
+// goPackage returns the name and the synopsis of the package documentation
+// of the Go package in dir
+func goPackage(root, dir string, files []string) (string, string) {
+	fset := token.NewFileSet()
+	name, synopsis := "", ""
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, filepath.Join(root, filepath.FromSlash(dir), file), nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			// Unparseable files are left for the compiler to report
+			continue
+		}
+		if name == "" || name == "main" && f.Name.Name != "main" {
+			name = f.Name.Name
+		}
+		if synopsis == "" && f.Doc != nil {
+			synopsis = new(doc.Package).Synopsis(f.Doc.Text())
+		}
+	}
+	return name, synopsis
+}
+
+// mainFile names the file of a Go main package to start reading from
+func mainFile(dir string, files []string) string {
+	file := files[0]
+	for _, f := range files {
+		if f == "main.go" {
+			file = f
+		}
+	}
+	return path.Join(dir, file)
+}
+
+// manifestPackage reads the package manifest in dir, if there is one,
+// recording the entry points it declares and returning its description
+func manifestPackage(root, dir string, m *Map) (string, bool) {
+	base := filepath.Join(root, filepath.FromSlash(dir))
+	if content, err := os.ReadFile(filepath.Join(base, "package.json")); err == nil {
+		var pkg struct {
+			Description string          `json:"description"`
+			Main        string          `json:"main"`
+			Bin         json.RawMessage `json:"bin"`
+		}
+		if json.Unmarshal(content, &pkg) != nil {
+			return "", true
+		}
+		if pkg.Main != "" {
+			m.EntryPoints = append(m.EntryPoints, EntryPoint{Path: path.Join(dir, pkg.Main), Kind: "package.json main"})
+		}
+		var bin string
+		var bins map[string]string
+		if json.Unmarshal(pkg.Bin, &bin) == nil && bin != "" {
+			m.EntryPoints = append(m.EntryPoints, EntryPoint{Path: path.Join(dir, bin), Kind: "package.json bin"})
+		} else if json.Unmarshal(pkg.Bin, &bins) == nil {
+			for name, file := range bins {
+				m.EntryPoints = append(m.EntryPoints, EntryPoint{Path: path.Join(dir, file), Kind: "package.json bin " + name})
+			}
+		}
+		return pkg.Description, true
+	}
+
+	if content, err := os.ReadFile(filepath.Join(base, "Cargo.toml")); err == nil {
+		for _, file := range []string{"src/main.rs"} {
+			if _, err := os.Stat(filepath.Join(base, filepath.FromSlash(file))); err == nil {
+				m.EntryPoints = append(m.EntryPoints, EntryPoint{Path: path.Join(dir, file), Kind: "Rust binary"})
+			}
+		}
+		bins, _ := filepath.Glob(filepath.Join(base, "src", "bin", "*.rs"))
+		for _, bin := range bins {
+			m.EntryPoints = append(m.EntryPoints, EntryPoint{Path: path.Join(dir, "src/bin", filepath.Base(bin)), Kind: "Rust binary"})
+		}
+		return tomlDescription(string(content)), true
+	}
+
+	if content, err := os.ReadFile(filepath.Join(base, "pyproject.toml")); err == nil {
+		for _, file := range []string{"manage.py", "__main__.py"} {
+			if _, err := os.Stat(filepath.Join(base, file)); err == nil {
+				m.EntryPoints = append(m.EntryPoints, EntryPoint{Path: path.Join(dir, file), Kind: "Python script"})
+			}
+		}
+		return tomlDescription(string(content)), true
+	}
+
+	if content, err := os.ReadFile(filepath.Join(base, "__init__.py")); err == nil {
+		if _, err := os.Stat(filepath.Join(base, "__main__.py")); err == nil {
+			m.EntryPoints = append(m.EntryPoints, EntryPoint{Path: path.Join(dir, "__main__.py"), Kind: "python -m " + path.Base(dir)})
+		}
+		// Only top-level Python packages are listed
+		if _, err := os.Stat(filepath.Join(base, "..", "__init__.py")); err == nil {
+			return "", false
+		}
+		return docstring(string(content)), true
+	}
+	return "", false
+}
+
+// tomlDescription returns the first description = "..." of a TOML manifest
+func tomlDescription(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(key) == "description" {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return ""
+}
+
+// docstring returns the first line of a Python module docstring
+func docstring(content string) string {
+	content = strings.TrimSpace(content)
+	for _, quote := range []string{`"""`, `'''`} {
+		if strings.HasPrefix(content, quote) {
+			text := strings.TrimSpace(strings.TrimPrefix(content, quote))
+			line, _, _ := strings.Cut(text, "\n")
+			return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), quote))
+		}
+	}
+	return ""
+}
+
+// hasReadme reports whether a directory contains a README
+func hasReadme(n *Node) bool {
+	for _, c := range n.Children {
+		if !c.Dir && strings.HasPrefix(strings.ToUpper(c.Name), "README") {
+			return true
+		}
+	}
+	return false
+}
+
+// readmeSummary returns the first sentence of the first paragraph of the
+// README in dir
+func readmeSummary(root, dir string) string {
+	for _, name := range []string{"README.md", "README", "README.txt", "readme.md"} {
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(dir), name))
+		if err != nil {
+			continue
+		}
+		var paragraph []string
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			switch {
+			case line == "" && len(paragraph) > 0:
+				return firstSentence(strings.Join(paragraph, " "))
+			case line == "", strings.HasPrefix(line, "#"), strings.HasPrefix(line, "!["),
+				strings.HasPrefix(line, "[!["), strings.HasPrefix(line, "<"), strings.HasPrefix(line, "```"):
+				// headings, badges and markup are not prose
+				if len(paragraph) > 0 {
+					return firstSentence(strings.Join(paragraph, " "))
+				}
+			default:
+				paragraph = append(paragraph, line)
+			}
+		}
+		return firstSentence(strings.Join(paragraph, " "))
+	}
+	return ""
+}
+
+// firstSentence cuts text after its first full stop
+func firstSentence(text string) string {
+	if i := strings.Index(text, ". "); i >= 0 {
+		return text[:i+1]
+	}
+	return text
+}
+
+// sortTree lists directories before files, each alphabetically
+func sortTree(n *Node) {
+	sort.Slice(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if a.Dir != b.Dir {
+			return a.Dir
+		}
+		return a.Name < b.Name
+	})
+	for _, c := range n.Children {
+		sortTree(c)
+	}
+}
@@ -0,0 +1,108 @@
+package repomap
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/johnayoung/go-agent-kit/internal/testutil"
+)
+
+func TestGenerate(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, map[string]string{
+		".gitignore":                  "/bin/\n*.log\n",
+		"go.mod":                      "module example.com/svc\n",
+		"README.md":                   "# svc\n\n[![CI](badge.svg)](ci)\n\nThe billing service. It charges customers.\n",
+		"cmd/svc/main.go":             "package main\n\nfunc main() {}\n",
+		"cmd/svc/flags.go":            "package main\n",
+		"internal/billing/billing.go": "// Package billing charges customers. It talks to Stripe.\npackage billing\n",
+		"internal/billing/x_test.go":  "// Package billing_test is not the package doc.\npackage billing_test\n",
+		"internal/store/store.go":     "package store\n",
+		"internal/store/README.md":    "Postgres persistence for invoices.\n",
+		"web/package.json":            `{"name": "web", "description": "Customer portal", "main": "index.js", "bin": {"portal": "bin/portal.js"}}`,
+		"web/index.js":                "",
+		"web/node_modules/dep/a.js":   "",
+		"tools/gen/pyproject.toml":    "[project]\nname = \"gen\"\ndescription = \"Code generator\"\n",
+		"tools/gen/manage.py":         "",
+		"scripts/README.md":           "# Scripts\n\nRelease helpers.\n",
+		"bin/svc":                     "",
+		"debug.log":                   "",
+		".github/workflows/ci.yml":    "",
+	})
+
+	m, err := Generate(root)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	expectedEntries := []EntryPoint{
+		{Path: "cmd/svc/main.go", Kind: "Go main package"},
+		{Path: "tools/gen/manage.py", Kind: "Python script"},
+		{Path: "web/bin/portal.js", Kind: "package.json bin portal"},
+		{Path: "web/index.js", Kind: "package.json main"},
+	}
+	if !reflect.DeepEqual(m.EntryPoints, expectedEntries) {
+		t.Errorf("Expected entry points %+v\ngot %+v", expectedEntries, m.EntryPoints)
+	}
+
+	expectedPackages := []Package{
+		{Path: ".", Summary: "The billing service."},
+		{Path: "cmd/svc"},
+		{Path: "internal/billing", Summary: "Package billing charges customers."},
+		{Path: "internal/store", Summary: "Postgres persistence for invoices."},
+		{Path: "scripts", Summary: "Release helpers."},
+		{Path: "tools/gen", Summary: "Code generator"},
+		{Path: "web", Summary: "Customer portal"},
+	}
+	if !reflect.DeepEqual(m.Packages, expectedPackages) {
+		t.Errorf("Expected packages %+v\ngot %+v", expectedPackages, m.Packages)
+	}
+
+	content := m.Markdown(0)
+	for _, want := range []string{"# Repository Map", "## Entry Points", "- `internal/billing`: Package billing charges customers.", "```text\ncmd/\n  svc/\n    flags.go\n    main.go\n"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected %q in the map:\n%s", want, content)
+		}
+	}
+	for _, unwanted := range []string{"\nbin/", "debug.log", "node_modules", ".github"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("Did not expect %q in the map:\n%s", unwanted, content)
+		}
+	}
+
+	section := m.Section(0)
+	if strings.Contains(section, "# Repository Map") || !strings.HasPrefix(section, "### Entry Points") {
+		t.Errorf("Expected an untitled section with third-level headings:\n%s", section)
+	}
+}
+
+func TestMarkdownBudget(t *testing.T) {
+	files := map[string]string{}
+	for i := 0; i < 40; i++ {
+		files[fmt.Sprintf("pkg/p%02d/deep/file.go", i)] = fmt.Sprintf("// Package p%02d does things.\npackage p%02d\n", i, i)
+	}
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, files)
+
+	m, err := Generate(root)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	full := m.Markdown(1 << 20)
+	if !strings.Contains(full, "file.go") {
+		t.Errorf("Expected the full tree within a large budget:\n%s", full)
+	}
+
+	for _, budget := range []int{1500, 600, 100} {
+		content := m.Markdown(budget)
+		if len(content) > budget {
+			t.Errorf("Map of %d bytes exceeds the budget of %d", len(content), budget)
+		}
+	}
+	if content := m.Markdown(1500); !strings.Contains(content, "more") {
+		t.Errorf("Expected the map to say what was left out:\n%s", content)
+	}
+}
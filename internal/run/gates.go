@@ -0,0 +1,77 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// gateWaitDelay bounds how long a timed-out gate waits for its output to
+// close after the kill
+const gateWaitDelay = 5 * time.Second
+
+// GateResult is the outcome of one gate command
+type GateResult struct {
+	Command  string        `json:"command"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"duration"`
+	// Output is the combined stdout and stderr; it is kept in the stage's
+	// gates log rather than the run record
+	Output string `json:"-"`
+}
+
+// Passed reports whether the command succeeded
+func (g GateResult) Passed() bool {
+	return g.ExitCode == 0
+}
+
+// RunGates executes each command in dir through the shell, continuing after
+// failures so every problem is reported at once. Commands that cannot be
+// started or exceed the timeout fail with exit code -1.
+func RunGates(dir string, commands []string, timeout time.Duration) []GateResult {
+	var results []GateResult
+	for _, command := range commands {
+		results = append(results, runGate(dir, command, timeout))
+	}
+	return results
+}
+
+// runGate executes one gate command
+func runGate(dir, command string, timeout time.Duration) GateResult {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+	cmd := exec.CommandContext(ctx, shell, flag, command)
+	cmd.Dir = dir
+	killOnCancel(cmd)
+	// Stop waiting for output from processes that survived the kill
+	cmd.WaitDelay = gateWaitDelay
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	start := time.Now()
+	err := cmd.Run()
+	result := GateResult{Command: command, Duration: time.Since(start).Round(time.Millisecond)}
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		result.ExitCode = -1
+		out.WriteString("\ngo-agent-kit: timed out after " + timeout.String() + "\n")
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		result.ExitCode = -1
+		out.WriteString("\ngo-agent-kit: " + err.Error() + "\n")
+	}
+	result.Output = out.String()
+	return result
+}
@@ -0,0 +1,79 @@
+package run
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunGates(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("gate commands in this test use sh")
+	}
+	dir, err := os.MkdirTemp("", "run-gates-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(dir+"/marker", []byte("here"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		command  string
+		exitCode int
+		output   string
+	}{
+		{name: "passes in the directory", command: "cat marker", exitCode: 0, output: "here"},
+		{name: "keeps stderr", command: "echo broken >&2; exit 3", exitCode: 3, output: "broken"},
+		{name: "missing command", command: "no-such-command-xyz", exitCode: 127},
+		{name: "times out", command: "sleep 5", exitCode: -1, output: "timed out"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := RunGates(dir, []string{tt.command}, 500*time.Millisecond)
+			if len(results) != 1 {
+				t.Fatalf("Expected one result, got %d", len(results))
+			}
+			g := results[0]
+			if g.Command != tt.command || g.ExitCode != tt.exitCode || g.Passed() != (tt.exitCode == 0) {
+				t.Errorf("Unexpected result %+v", g)
+			}
+			if !strings.Contains(g.Output, tt.output) {
+				t.Errorf("Expected %q in output %q", tt.output, g.Output)
+			}
+		})
+	}
+
+	// Every gate runs even after a failure
+	if results := RunGates(dir, []string{"false", "true"}, time.Second); len(results) != 2 || results[0].Passed() || !results[1].Passed() {
+		t.Errorf("Expected both gates to run: %+v", results)
+	}
+}
+
+func TestRunGatesKillsChildren(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("gate commands in this test use sh")
+	}
+	dir := t.TempDir()
+
+	// The background child would create late after the gate timed out
+	start := time.Now()
+	results := RunGates(dir, []string{"(sleep 1; touch late) & sleep 5"}, 200*time.Millisecond)
+	if results[0].ExitCode != -1 {
+		t.Fatalf("Expected the gate to time out, got %+v", results[0])
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected the timed-out gate to return promptly, took %s", elapsed)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if _, err := os.Stat(filepath.Join(dir, "late")); err == nil {
+		t.Error("Expected the child of the timed-out gate to be killed")
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package run
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// killOnCancel runs the command in its own process group and kills the whole
+// group when the context ends, so the children of the shell, such as the
+// test binaries of go test, do not outlive a timed-out gate
+func killOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package run
+
+import "os/exec"
+
+// killOnCancel kills the shell when the context ends. Windows has no process
+// groups to signal; WaitDelay stops the wait for children still holding the
+// output.
+func killOnCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,266 @@
+// Package run records workflow runs that span several chat sessions: which
+// workflow is being followed, the stage it has reached and the output of
+// every completed stage, so the next stage prompt can carry them forward.
+package run
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Dir holds one directory per run, relative to the repository root
+const Dir = ".agent-kit/runs"
+
+// currentFile names the run that commands act on by default
+const currentFile = "current"
+
+// recordFile is the run record inside a run directory
+const recordFile = "run.json"
+
+// validWorkflow matches the workflow names that may appear in a run ID, as
+// accepted by the template registry
+var validWorkflow = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// validID matches the IDs New creates: the start time, the workflow name and
+// the first words of the description. IDs name directories, so anything else
+// is refused before it is joined into a path.
+var validID = regexp.MustCompile(`^[0-9]{8}-[0-9]{6}-[a-z0-9][a-z0-9._-]*$`)
+
+// Run is a workflow being followed stage by stage
+type Run struct {
+	ID          string    `json:"id"`
+	Workflow    string    `json:"workflow"`
+	Description string    `json:"description"`
+	Stage       int       `json:"stage"` // number of the current stage
+	Stages      []Stage   `json:"stages"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+	Completed   time.Time `json:"completed,omitempty"`
+}
+
+// Stage is the progress of one stage of a run
+type Stage struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Slug      string    `json:"slug"`
+	Started   time.Time `json:"started,omitempty"`
+	Completed time.Time `json:"completed,omitempty"`
+	// Gates are the results of the latest gate check of the stage
+	Gates []GateResult `json:"gates,omitempty"`
+}
+
+// New starts a run of workflow at its first stage
+func New(workflow, description string, stages []Stage, now time.Time) (*Run, error) {
+	if !validWorkflow.MatchString(workflow) {
+		return nil, fmt.Errorf("invalid workflow name %q", workflow)
+	}
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("workflow %s has no stages", workflow)
+	}
+	r := &Run{
+		ID:          now.Format("20060102-150405") + "-" + workflow + slugSuffix(description),
+		Workflow:    workflow,
+		Description: description,
+		Stage:       stages[0].Number,
+		Stages:      stages,
+		Created:     now,
+		Updated:     now,
+	}
+	r.Stages[0].Started = now
+	return r, nil
+}
+
+// slugSuffix turns the first words of a description into an ID suffix
+func slugSuffix(description string) string {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(description)) {
+		word = strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, word)
+		if word != "" {
+			words = append(words, word)
+		}
+		if len(words) == 4 {
+			break
+		}
+	}
+	if len(words) == 0 {
+		return ""
+	}
+	return "-" + strings.Join(words, "-")
+}
+
+// Done reports whether every stage is complete
+func (r *Run) Done() bool {
+	return !r.Completed.IsZero()
+}
+
+// Current returns the current stage
+func (r *Run) Current() (*Stage, bool) {
+	for i := range r.Stages {
+		if r.Stages[i].Number == r.Stage {
+			return &r.Stages[i], true
+		}
+	}
+	return nil, false
+}
+
+// Advance completes the current stage and starts the next one, completing the
+// run after the last stage. It reports whether there is a next stage.
+func (r *Run) Advance(now time.Time) bool {
+	r.Updated = now
+	for i := range r.Stages {
+		if r.Stages[i].Number != r.Stage {
+			continue
+		}
+		r.Stages[i].Completed = now
+		if i+1 == len(r.Stages) {
+			r.Completed = now
+			return false
+		}
+		r.Stage = r.Stages[i+1].Number
+		r.Stages[i+1].Started = now
+		return true
+	}
+	return false
+}
+
+// File names a file of the stage inside the run directory, e.g.
+// 1-analysis.output.md
+func (s Stage) File(kind string) string {
+	return fmt.Sprintf("%d-%s.%s.md", s.Number, s.Slug, kind)
+}
+
+// path returns the run directory beneath root
+func path(root, id string) string {
+	return filepath.Join(root, filepath.FromSlash(Dir), id)
+}
+
+// Load reads the run with the given ID beneath root
+func Load(root, id string) (*Run, error) {
+	if !validID.MatchString(id) {
+		return nil, fmt.Errorf("invalid run ID %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(path(root, id), recordFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no run %q in %s", id, Dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run %s: %w", id, err)
+	}
+
+	var r Run
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse run %s: %w", id, err)
+	}
+	if r.ID != id {
+		return nil, fmt.Errorf("run %s records the ID %q", id, r.ID)
+	}
+	return &r, nil
+}
+
+// Save writes the run record beneath root
+func (r *Run) Save(root string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run: %w", err)
+	}
+	dir := path(root, r.ID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create run directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, recordFile), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write run: %w", err)
+	}
+	return nil
+}
+
+// WriteFile stores a file of the run, such as a stage prompt or output
+func (r *Run) WriteFile(root, name, content string) error {
+	if err := os.MkdirAll(path(root, r.ID), 0755); err != nil {
+		return fmt.Errorf("failed to create run directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(path(root, r.ID), name), []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// ReadFile returns a file of the run, or "" if it does not exist
+func (r *Run) ReadFile(root, name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(path(root, r.ID), name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return string(data), nil
+}
+
+// FilePath returns the slash path of a file of the run relative to root, for
+// messages
+func (r *Run) FilePath(name string) string {
+	return Dir + "/" + r.ID + "/" + name
+}
+
+// SetCurrent makes the run the default for later commands
+func SetCurrent(root, id string) error {
+	dir := filepath.Join(root, filepath.FromSlash(Dir))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", Dir, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, currentFile), []byte(id+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to record the current run: %w", err)
+	}
+	return nil
+}
+
+// Current loads the run commands act on by default. It returns nil without
+// an error when no run was started.
+func Current(root string) (*Run, error) {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(Dir), currentFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the current run: %w", err)
+	}
+	return Load(root, strings.TrimSpace(string(data)))
+}
+
+// List returns every run beneath root, most recently updated first
+func List(root string) ([]*Run, error) {
+	entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(Dir)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	var runs []*Run
+	for _, e := range entries {
+		if !e.IsDir() || !validID.MatchString(e.Name()) {
+			continue
+		}
+		r, err := Load(root, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, r)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Updated.After(runs[j].Updated) })
+	return runs, nil
+}
@@ -0,0 +1,143 @@
+package run
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testStages() []Stage {
+	return []Stage{
+		{Number: 1, Title: "CODEBASE ANALYSIS", Slug: "analysis"},
+		{Number: 2, Title: "IMPLEMENTATION PLAN", Slug: "plan"},
+	}
+}
+
+func TestNewAndAdvance(t *testing.T) {
+	start := time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC)
+	r, err := New("feat", "Add user auth (OAuth2)!", testStages(), start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ID != "20261017-153000-feat-add-user-auth-oauth2" {
+		t.Errorf("Unexpected ID %q", r.ID)
+	}
+	if r.Stage != 1 || !r.Stages[0].Started.Equal(start) {
+		t.Errorf("Expected the run to start at stage 1: %+v", r)
+	}
+
+	later := start.Add(time.Hour)
+	if !r.Advance(later) {
+		t.Fatal("Expected a second stage")
+	}
+	if r.Stage != 2 || !r.Stages[0].Completed.Equal(later) || !r.Stages[1].Started.Equal(later) || r.Done() {
+		t.Errorf("Expected the run at stage 2: %+v", r)
+	}
+
+	if r.Advance(later.Add(time.Hour)) {
+		t.Error("Did not expect a stage after the last one")
+	}
+	if !r.Done() {
+		t.Error("Expected the run to be complete")
+	}
+
+	if _, err := New("feat", "x", nil, start); err == nil {
+		t.Error("Expected an error for a workflow without stages")
+	}
+}
+
+func TestSaveLoadCurrentList(t *testing.T) {
+	root, err := os.MkdirTemp("", "run-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	if r, err := Current(root); err != nil || r != nil {
+		t.Fatalf("Expected no current run, got %v, %v", r, err)
+	}
+	if runs, err := List(root); err != nil || len(runs) != 0 {
+		t.Fatalf("Expected no runs, got %v, %v", runs, err)
+	}
+
+	start := time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC)
+	first, _ := New("feat", "add search", testStages(), start)
+	second, _ := New("fix", "crash", testStages(), start.Add(time.Minute))
+	for _, r := range []*Run{first, second} {
+		if err := r.Save(root); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := SetCurrent(root, first.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := Current(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(current, first) {
+		t.Errorf("Expected %+v\ngot %+v", first, current)
+	}
+
+	runs, err := List(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].ID != second.ID {
+		t.Errorf("Expected the most recently updated run first, got %+v", runs)
+	}
+
+	stage, _ := first.Current()
+	if err := first.WriteFile(root, stage.File("output"), "found it\n"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := first.ReadFile(root, "1-analysis.output.md"); err != nil || got != "found it\n" {
+		t.Errorf("Expected the stage output back, got %q, %v", got, err)
+	}
+	if got, err := first.ReadFile(root, "2-plan.output.md"); err != nil || got != "" {
+		t.Errorf("Expected no output for a pending stage, got %q, %v", got, err)
+	}
+
+	if _, err := Load(root, "missing"); err == nil {
+		t.Error("Expected an error for an unknown run")
+	}
+}
+
+func TestRejectsInvalidIDs(t *testing.T) {
+	root := t.TempDir()
+	start := time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC)
+
+	if _, err := New("../feat", "x", testStages(), start); err == nil {
+		t.Error("Expected an error for a workflow name that is not a file name")
+	}
+
+	for _, id := range []string{"", "..", "../../etc", "20261017-153000-feat/../../x", "feat"} {
+		if _, err := Load(root, id); err == nil || !strings.Contains(err.Error(), "invalid run ID") {
+			t.Errorf("Expected Load(%q) to reject the ID, got %v", id, err)
+		}
+	}
+
+	// The current file and run records may have been edited by hand
+	if err := SetCurrent(root, "../../outside"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Current(root); err == nil {
+		t.Error("Expected Current to reject an invalid current run")
+	}
+
+	r, _ := New("feat", "add search", testStages(), start)
+	r.ID = "20261017-153000-fix"
+	if err := r.Save(root); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(Dir), r.ID, "run.json"), []byte(`{"id": "../../outside"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(root, r.ID); err == nil {
+		t.Error("Expected Load to reject a record with another ID")
+	}
+}
@@ -0,0 +1,101 @@
+package symbols
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultBudget is the default size limit of a rendered index in bytes,
+// roughly 6,000 tokens
+const DefaultBudget = 24000
+
+// Detail levels, from everything down to bare signatures
+const (
+	detailNames = iota
+	detailTypes
+	detailSynopses
+	detailFull
+)
+
+// Markdown renders the index as a document of its own within budget bytes.
+// Doc comments and methods are left out, then whole packages, until it fits.
+func (ix *Index) Markdown(budget int) string {
+	return ix.fit(budget, "# Go Symbol Index\n\n", "##")
+}
+
+// Section renders the index without a title and with third-level headings,
+// to be embedded in a prompt
+func (ix *Index) Section(budget int) string {
+	return ix.fit(budget, "", "###")
+}
+
+// fit renders the index within budget bytes
+func (ix *Index) fit(budget int, title, heading string) string {
+	if budget <= 0 {
+		budget = DefaultBudget
+	}
+
+	var out string
+	for detail := detailFull; detail >= detailNames; detail-- {
+		out = ix.render(title, heading, detail, len(ix.Packages))
+		if len(out) <= budget {
+			return out
+		}
+	}
+	for n := len(ix.Packages) - 1; n >= 0; n-- {
+		out = ix.render(title, heading, detailNames, n)
+		if len(out) <= budget {
+			return out
+		}
+	}
+
+	// Even the package list does not fit: cut it at the last whole line
+	const note = "\n_Truncated to fit the size budget._\n"
+	cut := budget - len(note)
+	if cut < 0 {
+		cut = 0
+	}
+	out = out[:cut]
+	if i := strings.LastIndex(out, "\n"); i >= 0 {
+		out = out[:i]
+	}
+	return out + note
+}
+
+// render writes the first packages of the index at the given detail level
+func (ix *Index) render(title, heading string, detail, packages int) string {
+	var b strings.Builder
+	b.WriteString(title)
+	for _, p := range ix.Packages[:packages] {
+		fmt.Fprintf(&b, "%s `%s`\n\n", heading, p.ImportPath)
+		if p.Synopsis != "" && detail >= detailSynopses {
+			fmt.Fprintf(&b, "%s\n\n", p.Synopsis)
+		}
+		for _, t := range p.Types {
+			writeSymbol(&b, t, "", detail >= detailSynopses)
+			if detail < detailTypes && t.Kind != "interface" {
+				continue
+			}
+			for _, m := range t.Methods {
+				writeSymbol(&b, m, "  ", detail >= detailFull)
+			}
+		}
+		for _, f := range p.Funcs {
+			writeSymbol(&b, f, "", detail >= detailSynopses)
+		}
+		b.WriteString("\n")
+	}
+	if rest := len(ix.Packages) - packages; rest > 0 {
+		fmt.Fprintf(&b, "... and %d more packages\n", rest)
+	}
+	return b.String()
+}
+
+// writeSymbol writes one symbol as a list item
+func writeSymbol(b *strings.Builder, s Symbol, indent string, synopsis bool) {
+	if synopsis && s.Synopsis != "" {
+		fmt.Fprintf(b, "%s- `%s`: %s\n", indent, s.Signature, s.Synopsis)
+		return
+	}
+	fmt.Fprintf(b, "%s- `%s`\n", indent, s.Signature)
+}
@@ -0,0 +1,280 @@
+// Package symbols indexes the exported API of the Go packages in a
+// repository: types, interfaces, functions and their doc comments, read with
+// go/parser without building or downloading anything.
+package symbols
+
+import (
+	"bytes"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/johnayoung/go-agent-kit/internal/repomap"
+)
+
+// Index is the exported API of every Go package in a repository
+type Index struct {
+	Packages []Package
+}
+
+// Package is the exported API of one Go package
+type Package struct {
+	ImportPath string
+	Dir        string // slash-separated, relative to the repository root
+	Name       string
+	Synopsis   string
+	Types      []Symbol
+	Funcs      []Symbol
+}
+
+// Symbol is an exported type, function or method
+type Symbol struct {
+	Name      string
+	Kind      string // struct, interface, func, method or the underlying type
+	Signature string
+	Synopsis  string
+	// Methods are the methods of an interface, or of a type together with
+	// its constructors
+	Methods []Symbol
+}
+
+// Build indexes the Go packages beneath root. Test files, testdata, vendor,
+// hidden directories and gitignored files are left out.
+func Build(root string) (*Index, error) {
+	var ig repomap.Ignorer
+	if err := ig.AddExclude(root); err != nil {
+		return nil, err
+	}
+	dirs := map[string][]string{}
+	modules := map[string]string{}
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if name == "." {
+			name = ""
+		}
+
+		if d.IsDir() {
+			base := d.Name()
+			if name != "" && (base == "testdata" || base == "vendor" || base == "node_modules" ||
+				strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_") || ig.Ignored(name, true)) {
+				return filepath.SkipDir
+			}
+			return ig.AddFile(root, name)
+		}
+		if ig.Ignored(name, false) {
+			return nil
+		}
+
+		dir := path.Dir(name)
+		if dir == "." {
+			dir = ""
+		}
+		switch {
+		case d.Name() == "go.mod":
+			modules[dir] = modulePath(p)
+		case strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go"):
+			dirs[dir] = append(dirs[dir], p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ix := &Index{}
+	for dir, files := range dirs {
+		pkg, ok := indexPackage(files, importPath(modules, dir))
+		if !ok {
+			continue
+		}
+		pkg.Dir = dir
+		if pkg.Dir == "" {
+			pkg.Dir = "."
+		}
+		ix.Packages = append(ix.Packages, pkg)
+	}
+	sort.Slice(ix.Packages, func(i, j int) bool { return ix.Packages[i].Dir < ix.Packages[j].Dir })
+	return ix, nil
+}
+
+// modulePath reads the module path of a go.mod
+func modulePath(file string) string {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// importPath derives the import path of the package in dir from the
+// nearest enclosing module
+func importPath(modules map[string]string, dir string) string {
+	for d := dir; ; d = path.Dir(d) {
+		if d == "." {
+			d = ""
+		}
+		if module, ok := modules[d]; ok && module != "" {
+			rel := strings.TrimPrefix(strings.TrimPrefix(dir, d), "/")
+			return path.Join(module, rel)
+		}
+		if d == "" {
+			return dir
+		}
+	}
+}
+
+// indexPackage parses the files of one package directory. Directories
+// without an importable package, e.g. only main packages, are skipped.
+func indexPackage(files []string, importPath string) (Package, bool) {
+	fset := token.NewFileSet()
+	byPackage := map[string][]*ast.File{}
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			// Unparseable files are left for the compiler to report
+			continue
+		}
+		byPackage[f.Name.Name] = append(byPackage[f.Name.Name], f)
+	}
+
+	// Commands have no API for other packages to use
+	delete(byPackage, "main")
+	var parsed []*ast.File
+	for _, fs := range byPackage {
+		if len(fs) > len(parsed) {
+			parsed = fs
+		}
+	}
+	if len(parsed) == 0 {
+		return Package{}, false
+	}
+
+	d, err := doc.NewFromFiles(fset, parsed, importPath)
+	if err != nil {
+		return Package{}, false
+	}
+
+	pkg := Package{ImportPath: importPath, Name: d.Name, Synopsis: d.Synopsis(d.Doc)}
+	for _, t := range d.Types {
+		sym := typeSymbol(fset, d, t)
+		for _, f := range t.Funcs {
+			sym.Methods = append(sym.Methods, funcSymbol(fset, d, f, "func"))
+		}
+		for _, m := range t.Methods {
+			sym.Methods = append(sym.Methods, funcSymbol(fset, d, m, "method"))
+		}
+		pkg.Types = append(pkg.Types, sym)
+	}
+	for _, f := range d.Funcs {
+		pkg.Funcs = append(pkg.Funcs, funcSymbol(fset, d, f, "func"))
+	}
+	if len(pkg.Types) == 0 && len(pkg.Funcs) == 0 && pkg.Synopsis == "" {
+		return Package{}, false
+	}
+	return pkg, true
+}
+
+// typeSymbol describes an exported type; interfaces list their methods
+func typeSymbol(fset *token.FileSet, d *doc.Package, t *doc.Type) Symbol {
+	sym := Symbol{Name: t.Name, Synopsis: d.Synopsis(t.Doc)}
+	var spec *ast.TypeSpec
+	for _, s := range t.Decl.Specs {
+		if ts, ok := s.(*ast.TypeSpec); ok && ts.Name.Name == t.Name {
+			spec = ts
+		}
+	}
+	if spec == nil {
+		return sym
+	}
+
+	switch typ := spec.Type.(type) {
+	case *ast.StructType:
+		sym.Kind = "struct"
+	case *ast.InterfaceType:
+		sym.Kind = "interface"
+		for _, field := range typ.Methods.List {
+			if len(field.Names) == 0 {
+				// Embedded interface
+				sym.Methods = append(sym.Methods, Symbol{Name: node(fset, field.Type), Kind: "embedded", Signature: node(fset, field.Type)})
+				continue
+			}
+			for _, name := range field.Names {
+				if !name.IsExported() {
+					continue
+				}
+				sig := strings.TrimPrefix(node(fset, field.Type), "func")
+				sym.Methods = append(sym.Methods, Symbol{
+					Name:      name.Name,
+					Kind:      "method",
+					Signature: name.Name + sig,
+					Synopsis:  d.Synopsis(field.Doc.Text()),
+				})
+			}
+		}
+	default:
+		sym.Kind = node(fset, spec.Type)
+		assign := " "
+		if spec.Assign.IsValid() {
+			assign = " = "
+		}
+		sym.Signature = "type " + t.Name + typeParams(fset, spec) + assign + sym.Kind
+	}
+	if sym.Kind == "struct" || sym.Kind == "interface" {
+		sym.Signature = "type " + t.Name + typeParams(fset, spec) + " " + sym.Kind
+	}
+	return sym
+}
+
+// typeParams prints the type parameters of a generic type
+func typeParams(fset *token.FileSet, spec *ast.TypeSpec) string {
+	if spec.TypeParams == nil {
+		return ""
+	}
+	var params []string
+	for _, field := range spec.TypeParams.List {
+		var names []string
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		params = append(params, strings.Join(names, ", ")+" "+node(fset, field.Type))
+	}
+	return "[" + strings.Join(params, ", ") + "]"
+}
+
+// funcSymbol describes an exported function or method by its signature
+func funcSymbol(fset *token.FileSet, d *doc.Package, f *doc.Func, kind string) Symbol {
+	decl := *f.Decl
+	decl.Doc = nil
+	decl.Body = nil
+	return Symbol{Name: f.Name, Kind: kind, Signature: node(fset, &decl), Synopsis: d.Synopsis(f.Doc)}
+}
+
+// node prints an AST node on one line
+func node(fset *token.FileSet, n any) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, n); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
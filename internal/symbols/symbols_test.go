@@ -0,0 +1,149 @@
+package symbols
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/johnayoung/go-agent-kit/internal/testutil"
+)
+
+const storeSource = `// Package store persists orders. It wraps Postgres.
+package store
+
+import "context"
+
+// Store saves and loads orders
+type Store interface {
+	// Save persists an order
+	Save(ctx context.Context, o Order) error
+	Load(id string) (Order, error)
+	close() error
+	fmt.Stringer
+}
+
+// Order is a customer order
+type Order struct {
+	ID string
+}
+
+// NewOrder creates an order with a fresh ID
+func NewOrder() *Order { return &Order{} }
+
+// Total sums the order lines
+func (o *Order) Total() int { return 0 }
+
+func (o *Order) validate() error { return nil }
+
+// Status of an order
+type Status string
+
+// Set is a generic set
+type Set[T comparable] map[T]struct{}
+
+// Open connects to the database at dsn
+func Open(dsn string) (Store, error) { return nil, nil }
+
+func helper() {}
+`
+
+func TestBuild(t *testing.T) {
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, map[string]string{
+		".gitignore":                   "/generated/\n",
+		"go.mod":                       "module example.com/shop\n\ngo 1.22\n",
+		"internal/store/store.go":      storeSource,
+		"internal/store/store_test.go": "package store\n\n// TestOnly is test code\nfunc TestOnly() {}\n",
+		"cmd/shop/main.go":             "package main\n\nfunc Run() {}\n\nfunc main() {}\n",
+		"internal/store/testdata/x.go": "package x\n\nfunc Fixture() {}\n",
+		"generated/api.go":             "package generated\n\nfunc Generated() {}\n",
+		"tools/go.mod":                 "module example.com/tools\n",
+		"tools/lint/lint.go":           "package lint\n\n// Check lints\nfunc Check() {}\n",
+		"internal/empty/empty.go":      "package empty\n\nfunc unexported() {}\n",
+	})
+
+	ix, err := Build(root)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	expected := []Package{
+		{
+			ImportPath: "example.com/shop/internal/store",
+			Dir:        "internal/store",
+			Name:       "store",
+			Synopsis:   "Package store persists orders.",
+			Types: []Symbol{
+				{Name: "Order", Kind: "struct", Signature: "type Order struct", Synopsis: "Order is a customer order", Methods: []Symbol{
+					{Name: "NewOrder", Kind: "func", Signature: "func NewOrder() *Order", Synopsis: "NewOrder creates an order with a fresh ID"},
+					{Name: "Total", Kind: "method", Signature: "func (o *Order) Total() int", Synopsis: "Total sums the order lines"},
+				}},
+				{Name: "Set", Kind: "map[T]struct{}", Signature: "type Set[T comparable] map[T]struct{}", Synopsis: "Set is a generic set"},
+				{Name: "Status", Kind: "string", Signature: "type Status string", Synopsis: "Status of an order"},
+				{Name: "Store", Kind: "interface", Signature: "type Store interface", Synopsis: "Store saves and loads orders", Methods: []Symbol{
+					{Name: "Save", Kind: "method", Signature: "Save(ctx context.Context, o Order) error", Synopsis: "Save persists an order"},
+					{Name: "Load", Kind: "method", Signature: "Load(id string) (Order, error)"},
+					{Name: "fmt.Stringer", Kind: "embedded", Signature: "fmt.Stringer"},
+					// Constructors are grouped with their type, as in go doc
+					{Name: "Open", Kind: "func", Signature: "func Open(dsn string) (Store, error)", Synopsis: "Open connects to the database at dsn"},
+				}},
+			},
+		},
+		{
+			ImportPath: "example.com/tools/lint",
+			Dir:        "tools/lint",
+			Name:       "lint",
+			Funcs: []Symbol{
+				{Name: "Check", Kind: "func", Signature: "func Check()", Synopsis: "Check lints"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(ix.Packages, expected) {
+		t.Errorf("Expected %+v\ngot %+v", expected, ix.Packages)
+	}
+
+	content := ix.Markdown(0)
+	for _, want := range []string{
+		"# Go Symbol Index",
+		"## `example.com/shop/internal/store`\n\nPackage store persists orders.\n",
+		"- `type Store interface`: Store saves and loads orders\n  - `Save(ctx context.Context, o Order) error`: Save persists an order\n",
+		"  - `func Open(dsn string) (Store, error)`: Open connects to the database at dsn\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected %q in the index:\n%s", want, content)
+		}
+	}
+}
+
+func TestMarkdownBudget(t *testing.T) {
+	files := map[string]string{"go.mod": "module example.com/big\n"}
+	for i := 0; i < 30; i++ {
+		files[fmt.Sprintf("p%02d/p.go", i)] = fmt.Sprintf("// Package p%02d does things.\npackage p%02d\n\n// Thing is a thing with a long explanation of what it is for\ntype Thing struct{}\n\n// Do does it, at length\nfunc (t Thing) Do() error { return nil }\n", i, i)
+	}
+	root := t.TempDir()
+	testutil.WriteFiles(t, root, files)
+
+	ix, err := Build(root)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	full := ix.Markdown(1 << 20)
+	if !strings.Contains(full, "Do does it") {
+		t.Errorf("Expected doc comments within a large budget:\n%s", full)
+	}
+
+	for _, budget := range []int{4000, 2000, 500, 50} {
+		content := ix.Markdown(budget)
+		if len(content) > budget {
+			t.Errorf("Index of %d bytes exceeds the budget of %d", len(content), budget)
+		}
+	}
+	if content := ix.Markdown(4000); strings.Contains(content, "Do does it") || !strings.Contains(content, "- `type Thing struct`") {
+		t.Errorf("Expected doc comments to be dropped before types:\n%s", content)
+	}
+	if content := ix.Markdown(500); !strings.Contains(content, "more packages") {
+		t.Errorf("Expected the index to say what was left out:\n%s", content)
+	}
+}
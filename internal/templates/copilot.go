@@ -0,0 +1,80 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FrontMatter holds the YAML header that turns a markdown file into a
+// VS Code Copilot prompt file (slash command)
+type FrontMatter struct {
+	Mode        string
+	Description string
+	Tools       []string
+}
+
+// String formats the front matter as a YAML block delimited by --- lines
+func (f FrontMatter) String() string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	if f.Mode != "" {
+		fmt.Fprintf(&b, "mode: %s\n", yamlQuote(f.Mode))
+	}
+	if f.Description != "" {
+		fmt.Fprintf(&b, "description: %s\n", yamlQuote(f.Description))
+	}
+	if len(f.Tools) > 0 {
+		quoted := make([]string, len(f.Tools))
+		for i, tool := range f.Tools {
+			quoted[i] = yamlQuote(tool)
+		}
+		fmt.Fprintf(&b, "tools: [%s]\n", strings.Join(quoted, ", "))
+	}
+	b.WriteString("---\n")
+	return b.String()
+}
+
+// CopilotInput returns the Copilot Chat input variable for name. Copilot asks
+// the user for the value (showing placeholder) when the prompt file runs.
+func CopilotInput(name, placeholder string) string {
+	if placeholder == "" {
+		return fmt.Sprintf("${input:%s}", name)
+	}
+	return fmt.Sprintf("${input:%s:%s}", name, placeholder)
+}
+
+// CopilotContext returns a Context whose user-supplied fields are Copilot input
+// variables instead of literal values, so that installed prompt files ask for
+// them when invoked rather than containing raw template actions
+func CopilotContext(descriptionPlaceholder string) Context {
+	return Context{
+		Description: CopilotInput("description", descriptionPlaceholder),
+	}
+}
+
+// RenderPromptFile renders a template for installation as a Copilot prompt
+// file: the body is rendered with ctx and prefixed with the front matter
+func RenderPromptFile(templateName string, fm FrontMatter, ctx Context) (string, error) {
+	body, err := Render(templateName, ctx)
+	if err != nil {
+		return "", err
+	}
+	return fm.String() + "\n" + body, nil
+}
+
+// yamlQuote wraps s in single quotes, escaping embedded quotes YAML-style
+func yamlQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// RenderChatMode renders the workflow as a VS Code Copilot custom chat mode
+// (.chatmode.md), picked in the chat view rather than run as a slash command:
+// the body rendered with ctx, prefixed with the description and tools
+func (w Workflow) RenderChatMode(ctx Context) (string, error) {
+	body, err := w.Render(ctx)
+	if err != nil {
+		return "", err
+	}
+	fm := FrontMatter{Description: w.Description, Tools: w.FrontMatter().Tools}
+	return fm.String() + "\n" + body, nil
+}
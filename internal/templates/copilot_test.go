@@ -0,0 +1,72 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFrontMatterString(t *testing.T) {
+	tests := []struct {
+		name     string
+		fm       FrontMatter
+		expected string
+	}{
+		{
+			name: "all fields",
+			fm: FrontMatter{
+				Mode:        "agent",
+				Description: "Implement a feature",
+				Tools:       []string{"codebase", "editFiles"},
+			},
+			expected: "---\nmode: 'agent'\ndescription: 'Implement a feature'\ntools: ['codebase', 'editFiles']\n---\n",
+		},
+		{
+			name:     "quotes are escaped",
+			fm:       FrontMatter{Description: "Fix the user's bug"},
+			expected: "---\ndescription: 'Fix the user''s bug'\n---\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fm.String(); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCopilotInput(t *testing.T) {
+	if got := CopilotInput("description", "Describe it"); got != "${input:description:Describe it}" {
+		t.Errorf("Unexpected input variable: %s", got)
+	}
+	if got := CopilotInput("description", ""); got != "${input:description}" {
+		t.Errorf("Unexpected input variable without placeholder: %s", got)
+	}
+}
+
+func TestRenderPromptFile(t *testing.T) {
+	fm := FrontMatter{Mode: "agent", Description: "Implement a feature"}
+	result, err := RenderPromptFile("feat", fm, CopilotContext("Describe the feature"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.HasPrefix(result, "---\nmode: 'agent'\n") {
+		t.Errorf("Expected prompt file to start with front matter, got %q", result[:40])
+	}
+
+	expectedStrings := []string{
+		"Feature Implementation Workflow",
+		"implement: ${input:description:Describe the feature}",
+	}
+	for _, expected := range expectedStrings {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected to find '%s' in rendered prompt file", expected)
+		}
+	}
+
+	if strings.Contains(result, "{{") {
+		t.Error("Rendered prompt file should not contain raw template actions")
+	}
+}
@@ -0,0 +1,9 @@
+package templates
+
+import "fmt"
+
+// CursorRule formats content as a Cursor project rule (.cursor/rules/*.mdc)
+// that the agent pulls in when a request matches its description
+func CursorRule(description, content string) string {
+	return fmt.Sprintf("---\ndescription: %s\nglobs:\nalwaysApply: false\n---\n\n%s", yamlQuote(description), content)
+}
@@ -2,7 +2,34 @@ package templates
 
 import (
 	"embed"
+	"io/fs"
+	"sync"
 )
 
 //go:embed prompts/*.md
 var PromptFiles embed.FS
+
+// partials holds the blocks shared by the workflow templates, parsed
+// alongside every workflow
+//
+//go:embed partials.tmpl
+var partials string
+
+var (
+	embeddedOnce     sync.Once
+	embeddedRegistry *Registry
+	embeddedErr      error
+)
+
+// Embedded returns the registry of workflow templates built into the binary
+func Embedded() (*Registry, error) {
+	embeddedOnce.Do(func() {
+		prompts, err := fs.Sub(PromptFiles, "prompts")
+		if err != nil {
+			embeddedErr = err
+			return
+		}
+		embeddedRegistry, embeddedErr = NewRegistry(prompts)
+	})
+	return embeddedRegistry, embeddedErr
+}
@@ -0,0 +1,148 @@
+package templates
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ProjectDir holds project-local templates, relative to the repository root
+const ProjectDir = ".go-agent-kit/templates"
+
+// Layer is one source of workflow templates
+type Layer struct {
+	Name string // where the templates come from, e.g. "embedded"
+	FS   fs.FS
+}
+
+// UserDir returns the user-global template directory,
+// $XDG_CONFIG_HOME/go-agent-kit/templates (default ~/.config/go-agent-kit/templates)
+func UserDir() (string, error) {
+	config := os.Getenv("XDG_CONFIG_HOME")
+	if config == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate user config directory: %w", err)
+		}
+		config = filepath.Join(home, ".config")
+	}
+	return filepath.Join(config, "go-agent-kit", "templates"), nil
+}
+
+// Layers returns the template layers for the repository at root, lowest
+// precedence first: the embedded defaults, any template packs, the
+// user-global directory and the project-local directory. Directories that do
+// not exist are left out.
+func Layers(root string, packs ...Layer) ([]Layer, error) {
+	prompts, err := fs.Sub(PromptFiles, "prompts")
+	if err != nil {
+		return nil, err
+	}
+	layers := append([]Layer{{Name: "embedded", FS: prompts}}, packs...)
+
+	userDir, err := UserDir()
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range []string{userDir, filepath.Join(root, ProjectDir)} {
+		info, err := os.Stat(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template directory %s: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("template directory %s is not a directory", dir)
+		}
+		layers = append(layers, Layer{Name: dir, FS: os.DirFS(dir)})
+	}
+
+	return layers, nil
+}
+
+// Load returns the registry for the repository at root, combining every layer
+// returned by Layers
+func Load(root string, packs ...Layer) (*Registry, error) {
+	layers, err := Layers(root, packs...)
+	if err != nil {
+		return nil, err
+	}
+	return NewLayeredRegistry(layers...)
+}
+
+// NewLayeredRegistry combines template layers, later layers overriding
+// earlier ones by workflow name. An overriding template inherits the front
+// matter of the workflow it replaces, so it only needs to set the fields it
+// changes (or none, to replace just the body). Workflow definitions
+// (*.workflow.yaml) in a layer are compiled and override its templates.
+func NewLayeredRegistry(layers ...Layer) (*Registry, error) {
+	byName := make(map[string]Workflow)
+	for _, layer := range layers {
+		names, err := fs.Glob(layer.FS, "*.md")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list templates in %s: %w", layer.Name, err)
+		}
+		for _, file := range names {
+			content, err := fs.ReadFile(layer.FS, file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read template %s from %s: %w", file, layer.Name, err)
+			}
+
+			name := strings.TrimSuffix(file, path.Ext(file))
+			base, ok := byName[name]
+			if !ok {
+				base = Workflow{Name: name}
+			}
+			w, err := parseWorkflow(base, content)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", layer.Name, err)
+			}
+			w.Source = layer.Name
+			byName[name] = w
+		}
+
+		specs, err := fs.Glob(layer.FS, "*"+SpecExt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list workflow definitions in %s: %w", layer.Name, err)
+		}
+		for _, file := range specs {
+			content, err := fs.ReadFile(layer.FS, file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read workflow definition %s from %s: %w", file, layer.Name, err)
+			}
+
+			// A definition is complete, so it replaces a workflow outright
+			name := strings.TrimSuffix(file, SpecExt)
+			spec, err := ParseSpec(name, content)
+			if err != nil {
+				return nil, fmt.Errorf("%s: workflow definition %s: %w", layer.Name, file, err)
+			}
+			if spec.Name != name {
+				return nil, fmt.Errorf("%s: workflow definition %s: name %q must match the file name", layer.Name, file, spec.Name)
+			}
+			w, err := spec.Workflow()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", layer.Name, err)
+			}
+			w.Source = layer.Name
+			byName[name] = w
+		}
+	}
+
+	r := &Registry{}
+	for _, w := range byName {
+		r.workflows = append(r.workflows, w)
+	}
+	sort.SliceStable(r.workflows, func(i, j int) bool {
+		if r.workflows[i].Order != r.workflows[j].Order {
+			return r.workflows[i].Order < r.workflows[j].Order
+		}
+		return r.workflows[i].Name < r.workflows[j].Name
+	})
+	return r, nil
+}
@@ -0,0 +1,141 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNewLayeredRegistry(t *testing.T) {
+	base := fstest.MapFS{
+		"fix.md":  {Data: []byte("---\norder: 20\ntitle: Bug Fix Workflow\nexamples:\n  - fix the bug\n---\n# Fix {{.Description}}\n")},
+		"feat.md": {Data: []byte("---\norder: 10\ntitle: Feature Workflow\n---\n# Feat\n")},
+	}
+	org := fstest.MapFS{
+		// Replaces only the body; the front matter is inherited
+		"fix.md": {Data: []byte("# Org fix process for {{.Description}}\n")},
+	}
+	project := fstest.MapFS{
+		"fix.md":  {Data: []byte("---\ntitle: Project Fix Workflow\n---\n# Project fix\n")},
+		"docs.md": {Data: []byte("---\norder: 50\n---\n# Docs\n")},
+	}
+
+	registry, err := NewLayeredRegistry(
+		Layer{Name: "base", FS: base},
+		Layer{Name: "org", FS: org},
+	)
+	if err != nil {
+		t.Fatalf("NewLayeredRegistry failed: %v", err)
+	}
+	fix, _ := registry.Lookup("fix")
+	if fix.Source != "org" || fix.Title != "Bug Fix Workflow" || fix.Order != 20 || fix.Command() != "/fix fix the bug" {
+		t.Errorf("Expected org body with inherited front matter, got %+v", fix)
+	}
+	rendered, err := fix.Render(Context{Description: "login"})
+	if err != nil || rendered != "# Org fix process for login\n" {
+		t.Errorf("Unexpected render result %q (%v)", rendered, err)
+	}
+
+	registry, err = NewLayeredRegistry(
+		Layer{Name: "base", FS: base},
+		Layer{Name: "org", FS: org},
+		Layer{Name: "project", FS: project},
+	)
+	if err != nil {
+		t.Fatalf("NewLayeredRegistry failed: %v", err)
+	}
+	if got := strings.Join(registry.Names(), ","); got != "feat,fix,docs" {
+		t.Errorf("Expected feat,fix,docs, got %s", got)
+	}
+	fix, _ = registry.Lookup("fix")
+	if fix.Source != "project" || fix.Title != "Project Fix Workflow" || fix.Body != "# Project fix\n" {
+		t.Errorf("Expected project override, got %+v", fix)
+	}
+}
+
+func TestLayeredRegistryRejectsRenames(t *testing.T) {
+	base := fstest.MapFS{"fix.md": {Data: []byte("---\ntitle: Bug Fix Workflow\n---\n# Fix\n")}}
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{name: "template", fsys: fstest.MapFS{"fix.md": {Data: []byte("---\nname: hotfix\n---\n# Hotfix\n")}}},
+		{name: "definition", fsys: fstest.MapFS{"fix.workflow.yaml": {Data: []byte("name: hotfix\ndescription: d\nstages:\n  - title: A\n    goal: g\n")}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLayeredRegistry(Layer{Name: "base", FS: base}, Layer{Name: "project", FS: tt.fsys})
+			if err == nil || !strings.Contains(err.Error(), "must match the file name") {
+				t.Errorf("Expected the rename to be rejected, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "templates-load-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	configHome := filepath.Join(tempDir, "config")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	root := filepath.Join(tempDir, "repo")
+
+	files := map[string]string{
+		filepath.Join(configHome, "go-agent-kit", "templates", "fix.md"):      "# User fix\n",
+		filepath.Join(configHome, "go-agent-kit", "templates", "refactor.md"): "# User refactor\n",
+		filepath.Join(root, ProjectDir, "refactor.md"):                        "# Project refactor\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	registry, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"feat":     "",
+		"fix":      "# User fix\n",
+		"refactor": "# Project refactor\n",
+	}
+	for name, body := range expected {
+		w, ok := registry.Lookup(name)
+		if !ok {
+			t.Fatalf("Expected workflow %s", name)
+		}
+		if body == "" {
+			if w.Source != "embedded" {
+				t.Errorf("Expected %s from the embedded layer, got %s", name, w.Source)
+			}
+			continue
+		}
+		if w.Body != body {
+			t.Errorf("Expected %s body %q, got %q", name, body, w.Body)
+		}
+		if w.Description == "" {
+			t.Errorf("Expected %s to inherit the embedded front matter", name)
+		}
+	}
+
+	// Without any override directories only the embedded layer remains
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tempDir, "missing"))
+	layers, err := Layers(filepath.Join(tempDir, "other"))
+	if err != nil {
+		t.Fatalf("Layers failed: %v", err)
+	}
+	if len(layers) != 1 || layers[0].Name != "embedded" {
+		t.Errorf("Expected only the embedded layer, got %v", layers)
+	}
+}
@@ -0,0 +1,32 @@
+package templates
+
+import (
+	"regexp"
+	"strings"
+)
+
+// emphasis matches **bold**, __bold__ and `code` spans
+var emphasis = regexp.MustCompile("\\*\\*([^*]+)\\*\\*|__([^_]+)__|`([^`]+)`")
+
+// Plain converts a rendered workflow to plain text that survives being pasted
+// into any chat UI: heading markers, emphasis, code spans and fences are
+// removed, and Copilot's @workspace participant is dropped so the request
+// reads as an ordinary sentence.
+func Plain(markdown string) string {
+	var lines []string
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			line = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+		}
+		if rest, ok := strings.CutPrefix(line, "@workspace "); ok && rest != "" {
+			line = strings.ToUpper(rest[:1]) + rest[1:]
+		}
+		line = emphasis.ReplaceAllString(line, "$1$2$3")
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,12 @@
+package templates
+
+import "testing"
+
+func TestPlain(t *testing.T) {
+	markdown := "# Bug Fix Workflow\n\n## STAGE 1: DIAGNOSIS\n**Detected project facts** (confirm them):\n- Test: `go test ./...`\n- __Note__: keep it\n\n```text\ncmd/\n```\n\n@workspace examine the code\n"
+	expected := "Bug Fix Workflow\n\nSTAGE 1: DIAGNOSIS\nDetected project facts (confirm them):\n- Test: go test ./...\n- Note: keep it\n\ncmd/\n\nExamine the code\n"
+
+	if got := Plain(markdown); got != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
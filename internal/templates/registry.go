@@ -0,0 +1,232 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultMode and DefaultTools apply to workflows whose front matter does not
+// choose a Copilot chat mode or tool set
+var (
+	DefaultMode  = "agent"
+	DefaultTools = []string{"codebase", "editFiles", "findTestFiles", "problems", "runCommands", "search", "usages"}
+)
+
+// validName matches workflow names, which become file names, slash commands
+// and part of run IDs
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// Workflow describes a workflow template. Everything except Body is read
+// from the YAML front matter at the top of the template file.
+type Workflow struct {
+	Name        string   `yaml:"name"`
+	Order       int      `yaml:"order,omitempty"`
+	Title       string   `yaml:"title,omitempty"`
+	Description string   `yaml:"description,omitempty"` // shown in the Copilot slash command picker
+	Summary     string   `yaml:"summary,omitempty"`     // when to use the command
+	Placeholder string   `yaml:"placeholder,omitempty"` // hint shown when Copilot asks for the description
+	Usage       string   `yaml:"usage,omitempty"`       // argument hint shown after the command
+	Examples    []string `yaml:"examples,omitempty"`    // example arguments, without the slash command
+	Overview    string   `yaml:"overview,omitempty"`    // introduces the stage list
+	Stages      []Stage  `yaml:"stages,omitempty"`
+	Mode        string   `yaml:"mode,omitempty"`
+	Tools       []string `yaml:"tools,omitempty"`
+
+	// Body is the template text following the front matter
+	Body string `yaml:"-"`
+	// Source names the template layer the workflow was loaded from
+	Source string `yaml:"-"`
+}
+
+// Stage summarizes one step of a workflow
+type Stage struct {
+	Title   string `yaml:"title"`
+	Summary string `yaml:"summary,omitempty"`
+	// Gates must succeed before a run moves past the stage: kinds from
+	// GateKinds, run as the commands detected in the project
+	Gates []string `yaml:"gates,omitempty"`
+}
+
+// GateKinds are the gates a template may declare. Templates can come from
+// packs and other people's repositories, so they name a kind of check and
+// never a command line; only the project config sets commands.
+var GateKinds = []string{"build", "test", "lint", "format", "check"}
+
+// Command returns the slash command invoking the workflow with its first
+// example, e.g. "/feat add user authentication system"
+func (w Workflow) Command() string {
+	if len(w.Examples) == 0 {
+		return "/" + w.Name
+	}
+	return strings.TrimSpace("/" + w.Name + " " + w.Examples[0])
+}
+
+// FrontMatter returns the Copilot prompt file front matter for the workflow
+func (w Workflow) FrontMatter() FrontMatter {
+	fm := FrontMatter{Mode: w.Mode, Description: w.Description, Tools: w.Tools}
+	if fm.Mode == "" {
+		fm.Mode = DefaultMode
+	}
+	if len(fm.Tools) == 0 {
+		fm.Tools = DefaultTools
+	}
+	return fm
+}
+
+// Registry enumerates the workflow templates in a file system
+type Registry struct {
+	workflows []Workflow
+}
+
+// NewRegistry loads every *.md template at the top level of fsys
+func NewRegistry(fsys fs.FS) (*Registry, error) {
+	return NewLayeredRegistry(Layer{Name: "templates", FS: fsys})
+}
+
+// ParseWorkflow splits a template file into its front matter and body. The
+// workflow is named after the file (without extension); a front matter name
+// must match it.
+func ParseWorkflow(name string, content []byte) (Workflow, error) {
+	return parseWorkflow(Workflow{Name: name}, content)
+}
+
+// parseWorkflow reads a template file on top of base: fields set in the
+// front matter replace those of base and the body always does
+func parseWorkflow(base Workflow, content []byte) (Workflow, error) {
+	w := base
+
+	// Templates saved on Windows have CRLF line endings
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	if rest, ok := strings.CutPrefix(text, "---\n"); ok {
+		header, body, found := strings.Cut(rest, "\n---\n")
+		if !found {
+			return w, fmt.Errorf("template %s: front matter is missing its closing ---", base.Name)
+		}
+		if err := yaml.Unmarshal([]byte(header), &w); err != nil {
+			return w, fmt.Errorf("template %s: invalid front matter: %w", base.Name, err)
+		}
+		text = strings.TrimPrefix(body, "\n")
+	}
+
+	if w.Name != base.Name {
+		return w, fmt.Errorf("template %s: front matter name %q must match the file name", base.Name, w.Name)
+	}
+	if !validName.MatchString(w.Name) {
+		return w, fmt.Errorf("template %s: name %q must be lowercase letters, digits, '.', '_' or '-'", base.Name, w.Name)
+	}
+	for _, st := range w.Stages {
+		for _, g := range st.Gates {
+			if !contains(GateKinds, g) {
+				return w, fmt.Errorf("template %s: stage %s: gate %q must be one of %s", base.Name, st.Title, g, strings.Join(GateKinds, ", "))
+			}
+		}
+	}
+	w.Body = text
+	if w.Title == "" {
+		w.Title = w.Name
+	}
+	return w, nil
+}
+
+// Workflows returns every workflow, ordered by their front matter order
+func (r *Registry) Workflows() []Workflow {
+	return append([]Workflow(nil), r.workflows...)
+}
+
+// Names returns the names of every workflow in order
+func (r *Registry) Names() []string {
+	names := make([]string, len(r.workflows))
+	for i, w := range r.workflows {
+		names[i] = w.Name
+	}
+	return names
+}
+
+// Lookup returns the workflow with the given name
+func (r *Registry) Lookup(name string) (Workflow, bool) {
+	for _, w := range r.workflows {
+		if w.Name == name {
+			return w, true
+		}
+	}
+	return Workflow{}, false
+}
+
+// Render executes the named workflow template with the given context
+func (r *Registry) Render(name string, ctx Context) (string, error) {
+	w, ok := r.Lookup(name)
+	if !ok {
+		return "", fmt.Errorf("failed to read template %s: no such workflow", name)
+	}
+	return w.Render(ctx)
+}
+
+// funcs are the functions available to templates beyond the text/template
+// builtins
+var funcs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// Render executes the workflow template body with the given context
+func (w Workflow) Render(ctx Context) (string, error) {
+	// Parse the template alongside the shared partials
+	tmpl, err := parsePartials()
+	if err != nil {
+		return "", err
+	}
+	name := w.Name
+	if tmpl.Lookup(name) != nil {
+		// A workflow named like a partial must not replace it
+		name += ".md"
+	}
+	tmpl, err = tmpl.New(name).Parse(w.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", w.Name, err)
+	}
+
+	// Execute the template with the context
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ctx); err != nil {
+		return "", fmt.Errorf("failed to execute template %s: %w", w.Name, err)
+	}
+
+	return buf.String(), nil
+}
+
+var (
+	partialsOnce sync.Once
+	partialsTmpl *template.Template
+	partialsErr  error
+)
+
+// parsePartials returns a fresh copy of the shared partials to parse a
+// workflow into
+func parsePartials() (*template.Template, error) {
+	partialsOnce.Do(func() {
+		partialsTmpl, partialsErr = template.New("partials").Funcs(funcs).Parse(partials)
+		if partialsErr != nil {
+			partialsErr = fmt.Errorf("failed to parse partials: %w", partialsErr)
+		}
+	})
+	if partialsErr != nil {
+		return nil, partialsErr
+	}
+	return partialsTmpl.Clone()
+}
+
+// RenderPromptFile renders the workflow for installation as a Copilot prompt
+// file: the body rendered with ctx, prefixed with its front matter
+func (w Workflow) RenderPromptFile(ctx Context) (string, error) {
+	body, err := w.Render(ctx)
+	if err != nil {
+		return "", err
+	}
+	return w.FrontMatter().String() + "\n" + body, nil
+}
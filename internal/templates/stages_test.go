@@ -0,0 +1,150 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+const stagedWorkflow = "# Demo Workflow\n\n## STAGE 1: CODEBASE ANALYSIS\nAnalyze: add search\n\n## STAGE 2: IMPLEMENTATION PLAN\nPlan it.\n\n## Notes inside a stage\nStill stage 2.\n\n## STAGE 3: TESTING\nTest it.\n\n```markdown\n## STAGE 9: NOT A STAGE\n```\n\n## Success Criteria\n- [ ] Done\n"
+
+func TestSplitStages(t *testing.T) {
+	doc := SplitStages(stagedWorkflow)
+
+	if doc.Preamble != "# Demo Workflow\n\n" {
+		t.Errorf("Unexpected preamble %q", doc.Preamble)
+	}
+	if doc.Appendix != "## Success Criteria\n- [ ] Done\n" {
+		t.Errorf("Unexpected appendix %q", doc.Appendix)
+	}
+
+	expected := []struct {
+		number int
+		title  string
+		slug   string
+		text   string
+	}{
+		{1, "CODEBASE ANALYSIS", "analysis", "## STAGE 1: CODEBASE ANALYSIS\nAnalyze: add search\n\n"},
+		{2, "IMPLEMENTATION PLAN", "plan", "## STAGE 2: IMPLEMENTATION PLAN\nPlan it.\n\n## Notes inside a stage\nStill stage 2.\n\n"},
+		{3, "TESTING", "testing", "## STAGE 3: TESTING\nTest it.\n\n```markdown\n## STAGE 9: NOT A STAGE\n```\n\n"},
+	}
+	if len(doc.Stages) != len(expected) {
+		t.Fatalf("Expected %d stages, got %+v", len(expected), doc.Stages)
+	}
+	for i, want := range expected {
+		s := doc.Stages[i]
+		if s.Number != want.number || s.Title != want.title || s.Slug() != want.slug || s.Text != want.text {
+			t.Errorf("Stage %d: expected %+v, got %+v (slug %q)", i, want, s, s.Slug())
+		}
+	}
+}
+
+func TestStagePrompt(t *testing.T) {
+	doc := SplitStages(stagedWorkflow)
+
+	first, err := doc.StagePrompt(1, "add search")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(first, "**Task:**") {
+		t.Errorf("Did not expect the task to be repeated when the stage states it:\n%s", first)
+	}
+	if !strings.HasSuffix(first, "This is stage 1 of 3. Complete only this stage, then stop and wait for review before starting stage 2.\n") {
+		t.Errorf("Expected an instruction to stop after the stage:\n%s", first)
+	}
+
+	second, err := doc.StagePrompt(2, "add search")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# Demo Workflow\n\n**Task:** add search\n\n## STAGE 2", "## Success Criteria"} {
+		if !strings.Contains(second, want) {
+			t.Errorf("Expected %q in stage 2:\n%s", want, second)
+		}
+	}
+	if strings.Contains(second, "STAGE 1") || strings.Contains(second, "STAGE 3") {
+		t.Errorf("Expected only stage 2:\n%s", second)
+	}
+
+	last, err := doc.StagePrompt(3, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(last, "This is the last stage (3 of 3).") {
+		t.Errorf("Expected the last stage to say so:\n%s", last)
+	}
+
+	if _, err := doc.StagePrompt(4, ""); err == nil {
+		t.Error("Expected an error for a missing stage")
+	}
+}
+
+func TestSplitEmbeddedStages(t *testing.T) {
+	registry, err := Embedded()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range registry.Workflows() {
+		body, err := w.Render(Context{Description: "x"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc := SplitStages(body)
+		if len(doc.Stages) != len(w.Stages) {
+			t.Errorf("%s: expected %d stages, found %d", w.Name, len(w.Stages), len(doc.Stages))
+		}
+		for i, s := range doc.Stages {
+			if i < len(w.Stages) && s.Title != w.Stages[i].Title {
+				t.Errorf("%s: stage %d is %q, front matter says %q", w.Name, i+1, s.Title, w.Stages[i].Title)
+			}
+		}
+	}
+}
+
+func TestStageGatesAndRemediation(t *testing.T) {
+	doc := SplitStages(stagedWorkflow)
+	doc.Stages[1].Gates = []string{"make build", "make test"}
+
+	prompt, err := doc.StagePrompt(2, "add search")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(prompt, "## Stage Gates\n\nThe stage is only done once these commands pass. Run them before reporting back:\n\n- `make build`\n- `make test`\n\n---") {
+		t.Errorf("Expected the gates before the footer:\n%s", prompt)
+	}
+
+	long := strings.Repeat("ok\n", 3000) + "--- FAIL: TestSearch\n"
+	remediation, err := doc.RemediationPrompt(2, "add search", []GateFailure{
+		{Command: "make test", ExitCode: 2, Output: long},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"**Task:** add search",
+		"## Fix Failing Gates: Stage 2 (IMPLEMENTATION PLAN)",
+		"### `make test` (exit code 2)\n\n```text\n...\nok\n",
+		"--- FAIL: TestSearch\n```",
+	} {
+		if !strings.Contains(remediation, want) {
+			t.Errorf("Expected %q in remediation:\n%s", want, remediation)
+		}
+	}
+	if len(remediation) > len(long) {
+		t.Errorf("Expected the output to be cut to its end, got %d bytes", len(remediation))
+	}
+
+	// Output holding a fence of its own cannot close the quote early
+	remediation, err = doc.RemediationPrompt(2, "", []GateFailure{
+		{Command: "make lint", ExitCode: 1, Output: "README.md:3: unclosed fence\n```go\n````\n"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(remediation, "\n`````text\nREADME.md:3: unclosed fence\n```go\n````\n`````\n") {
+		t.Errorf("Expected a fence longer than the output's backtick runs:\n%s", remediation)
+	}
+
+	if _, err := doc.RemediationPrompt(4, "", nil); err == nil {
+		t.Error("Expected an error for a missing stage")
+	}
+}
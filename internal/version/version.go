@@ -0,0 +1,7 @@
+// Package version holds the go-agent-kit release version.
+package version
+
+// Version is the go-agent-kit release. It is recorded in install manifests so
+// later commands can tell which release generated a file, and can be set at
+// build time with -ldflags "-X github.com/johnayoung/go-agent-kit/internal/version.Version=..."
+var Version = "0.2.0"